@@ -0,0 +1,122 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report JSON field names instead of Go field names in validation errors
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// Binding converts an error returned by c.ShouldBindJSON into an *Error.
+// Malformed JSON becomes a 400 Bad Request with the code invalid_body, while
+// wrongly typed or invalid fields become a 422 Unprocessable Entity with per-field details.
+func Binding(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fieldErr.Namespace()),
+				Code:    fieldErr.Tag(),
+				Message: validationMessage(fieldErr),
+			})
+		}
+		return &Error{
+			Status: http.StatusUnprocessableEntity,
+			Code:   CodeValidationFailed,
+			Detail: "Request body contains invalid fields",
+			Fields: fields,
+			Err:    err,
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &Error{
+			Status: http.StatusUnprocessableEntity,
+			Code:   CodeValidationFailed,
+			Detail: "Request body contains invalid fields",
+			Fields: []FieldError{{
+				Field:   typeErr.Field,
+				Code:    "type",
+				Message: fmt.Sprintf("must be of type %s", jsonType(typeErr.Type)),
+			}},
+			Err: err,
+		}
+	}
+
+	return Wrap(err, http.StatusBadRequest, CodeInvalidBody, "Invalid request body format")
+}
+
+// fieldPath strips the struct name from a validator namespace such as "About.content".
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// validationMessage returns a human-readable message for a failed validation rule.
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		return "must be at most " + fieldErr.Param() + " characters long"
+	case "min":
+		return "must be at least " + fieldErr.Param() + " characters long"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "datetime":
+		if fieldErr.Param() == "2006-01" {
+			return "must be a month in the YYYY-MM format"
+		}
+		return "must be a date in the " + fieldErr.Param() + " layout"
+	default:
+		return "failed the " + fieldErr.Tag() + " rule"
+	}
+}
+
+// jsonType returns the JSON name of the Go type expected by a field.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,146 @@
+// Package apperrors defines the error type returned by handlers and its RFC 7807 representation.
+//
+// Handlers report failures with c.Error(err) and stop; the ErrorHandler middleware turns the
+// last error into an application/problem+json response. Errors that are not *Error are treated
+// as internal errors, so implementation details never reach the client.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+)
+
+// Code is a stable, machine-readable error code returned in the "code" member of a problem.
+type Code string
+
+// Error codes returned by the API. Codes are part of the API contract and must not change.
+const (
+	CodeInvalidBody         Code = "invalid_body"          // The request body is not valid JSON
+	CodeBodyTooLarge        Code = "body_too_large"        // The request body exceeds the size limit
+	CodeUnsupportedMedia    Code = "unsupported_media"     // The uploaded file is not of a supported type
+	CodeValidationFailed    Code = "validation_failed"     // The request is well-formed but has invalid fields
+	CodeInvalidParameter    Code = "invalid_parameter"     // A query or path parameter is invalid
+	CodeNotFound            Code = "not_found"             // The requested entity does not exist
+	CodeRouteNotFound       Code = "route_not_found"       // No route matches the request path
+	CodeMethodNotAllowed    Code = "method_not_allowed"    // The route does not support the request method
+	CodeAPIKeyRequired      Code = "api_key_required"      // The x-api-key header is missing
+	CodeAPIKeyInvalid       Code = "api_key_invalid"       // The x-api-key header is wrong
+	CodeAdminKeyRequired    Code = "admin_key_required"    // The x-admin-key header is missing
+	CodeAdminKeyInvalid     Code = "admin_key_invalid"     // The x-admin-key header is wrong
+	CodeMetricsTokenInvalid Code = "metrics_token_invalid" // The metrics bearer token is missing or wrong
+	CodeCacheError          Code = "cache_error"           // Redis could not be used
+	CodeConflict            Code = "conflict"              // The entity conflicts with an existing one
+	CodeDatabaseError       Code = "database_error"        // The database query failed
+	CodeDatabaseUnavailable Code = "database_unavailable"  // The database cannot be reached
+	CodeStorageUnavailable  Code = "storage_unavailable"   // The file storage cannot be used
+	CodeConfigurationError  Code = "configuration_error"   // The service is misconfigured
+	CodeInternalError       Code = "internal_error"        // Any other unexpected failure
+)
+
+// FieldError describes why a single field of a request was rejected.
+type FieldError struct {
+	Field   string `json:"field"`   // JSON name or parameter name of the field
+	Code    string `json:"code"`    // Failed rule, e.g. required, max or type
+	Message string `json:"message"` // Human-readable explanation
+}
+
+// Error is an API error with an HTTP status, a stable code and an optional cause.
+// The cause is logged but never sent to the client.
+type Error struct {
+	Status int          // HTTP status code of the response
+	Code   Code         // Stable error code
+	Detail string       // Human-readable explanation sent to the client
+	Fields []FieldError // Per-field validation details
+	Err    error        // Underlying cause, if any
+}
+
+// Error returns the detail and the cause of the error.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error with the given status, code and detail.
+func New(status int, code Code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Wrap creates an error with the given status, code and detail caused by err.
+func Wrap(err error, status int, code Code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail, Err: err}
+}
+
+// NotFound creates a 404 Not Found error.
+func NotFound(detail string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+// InvalidParameter creates a 400 Bad Request error for a single query or path parameter.
+func InvalidParameter(name string, detail string) *Error {
+	return &Error{
+		Status: http.StatusBadRequest,
+		Code:   CodeInvalidParameter,
+		Detail: detail,
+		Fields: []FieldError{{Field: name, Code: "invalid", Message: detail}},
+	}
+}
+
+// Validation creates a 422 Unprocessable Entity error for invalid fields of the request body.
+func Validation(fields []FieldError) *Error {
+	return &Error{
+		Status: http.StatusUnprocessableEntity,
+		Code:   CodeValidationFailed,
+		Detail: "Request body contains invalid fields",
+		Fields: fields,
+	}
+}
+
+// Database creates a 500 Internal Server Error caused by a failed database query.
+func Database(err error) *Error {
+	return Wrap(err, http.StatusInternalServerError, CodeDatabaseError, "Error accessing the database")
+}
+
+// Cache creates a 500 Internal Server Error caused by a failed Redis command.
+func Cache(err error) *Error {
+	return Wrap(err, http.StatusInternalServerError, CodeCacheError, "Error accessing cache")
+}
+
+// Internal creates a 500 Internal Server Error caused by err.
+func Internal(err error) *Error {
+	return Wrap(err, http.StatusInternalServerError, CodeInternalError, "Internal Server Error")
+}
+
+// From converts any error into an *Error. Errors that are not *Error become internal errors.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// FromDatabase converts an error returned by a repository into an *Error.
+// A missing record becomes a 404 Not Found, a unique constraint violation a 409 Conflict,
+// a lost connection a 503 Service Unavailable and any other failure a database error.
+func FromDatabase(err error) *Error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return Wrap(err, http.StatusNotFound, CodeNotFound, "Content not found")
+	case errors.Is(err, repositories.ErrConflict):
+		return Wrap(err, http.StatusConflict, CodeConflict, "Content conflicts with an existing entry")
+	case errors.Is(err, repositories.ErrUnavailable):
+		return Wrap(err, http.StatusServiceUnavailable, CodeDatabaseUnavailable, "Database is unavailable, try again later")
+	default:
+		return Database(err)
+	}
+}
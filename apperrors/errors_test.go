@@ -0,0 +1,34 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+)
+
+func TestFromDatabase(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   Code
+	}{
+		{fmt.Errorf("find: %w", repositories.ErrNotFound), http.StatusNotFound, CodeNotFound},
+		{fmt.Errorf("create: %w", repositories.ErrConflict), http.StatusConflict, CodeConflict},
+		{fmt.Errorf("create: %w", repositories.ErrUnavailable), http.StatusServiceUnavailable, CodeDatabaseUnavailable},
+		{errors.New("pq: disk full"), http.StatusInternalServerError, CodeDatabaseError},
+	}
+	for _, tt := range tests {
+		got := FromDatabase(tt.err)
+		if got.Status != tt.status || got.Code != tt.code || !errors.Is(got, tt.err) {
+			t.Errorf("FromDatabase(%v) = %d %s, want %d %s wrapping the cause", tt.err, got.Status, got.Code, tt.status, tt.code)
+		}
+		// The cause is logged, never sent
+		if problem := NewProblem(got, "/", ""); strings.Contains(problem.Detail, tt.err.Error()) {
+			t.Errorf("problem detail of %v leaks the cause", tt.err)
+		}
+	}
+}
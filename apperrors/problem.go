@@ -0,0 +1,33 @@
+package apperrors
+
+import "net/http"
+
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 problem details document sent for every error response.
+// It extends the standard members with a stable error code, the request ID and field details.
+type Problem struct {
+	Type      string       `json:"type"`             // Problem type, always about:blank
+	Title     string       `json:"title"`            // HTTP status text
+	Status    int          `json:"status"`           // HTTP status code
+	Detail    string       `json:"detail,omitempty"` // Human-readable explanation
+	Instance  string       `json:"instance"`         // Path of the request
+	Code      Code         `json:"code"`             // Stable error code
+	RequestID string       `json:"requestId"`        // Correlation ID of the request
+	Errors    []FieldError `json:"errors,omitempty"` // Per-field validation details
+}
+
+// NewProblem builds the problem document for err.
+func NewProblem(err *Error, instance string, requestID string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    err.Detail,
+		Instance:  instance,
+		Code:      err.Code,
+		RequestID: requestID,
+		Errors:    err.Fields,
+	}
+}
@@ -0,0 +1,179 @@
+// Package cache provides the response cache shared by the services.
+//
+// Values are stored as JSON under keys of the form "<entity>:<id>" or "<entity>:all", followed by
+// ":<locale>" for translated content, so that the cache metrics can report them per entity.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Cache stores JSON-encoded values for a fixed time to live.
+type Cache interface {
+	// Get decodes the value stored under key into dest and reports whether it was found.
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+	// Set stores value under key.
+	Set(ctx context.Context, key string, value interface{}) error
+	// Replace stores values under their keys and removes the stale keys in a single transaction,
+	// so that readers see either the old or the new state, never a mix of both.
+	Replace(ctx context.Context, values map[string]interface{}, stale ...string) error
+	// Delete removes the given keys.
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes every key that starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// redisCache is the Redis implementation of Cache.
+type redisCache struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+// NewRedisCache returns a Cache backed by rdb whose entries expire after ttl.
+// It panics when rdb is nil, so that a missing dependency fails at startup.
+func NewRedisCache(rdb *redis.Client, ttl time.Duration) Cache {
+	if rdb == nil {
+		panic("cache: nil Redis client")
+	}
+	return &redisCache{rdb: rdb, ttl: ttl}
+}
+
+func (r *redisCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := r.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, key, data, r.ttl).Err()
+}
+
+func (r *redisCache) Replace(ctx context.Context, values map[string]interface{}, stale ...string) error {
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		encoded[key] = data
+	}
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, data := range encoded {
+			pipe.Set(ctx, key, data, r.ttl)
+		}
+		if len(stale) > 0 {
+			pipe.Del(ctx, stale...)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+func (r *redisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.rdb.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := r.rdb.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Load returns the value cached under key. On a cache miss, it calls load and caches the result.
+//
+// The cache never fails a read: when Redis cannot be used or holds an undecodable value,
+// the error is logged and the value is loaded from the source instead.
+func Load[T any](ctx context.Context, c Cache, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	found, err := c.Get(ctx, key, &value)
+	switch {
+	case err != nil:
+		log.Printf("Error reading key %s from cache: %v", key, err)
+	case found:
+		log.Printf("Cache hit for key %s", key)
+		return value, nil
+	default:
+		log.Printf("Cache miss for key %s", key)
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	if err := c.Set(ctx, key, value); err != nil {
+		log.Printf("Error saving to Redis: %v", err)
+	} else {
+		log.Printf("Key %s saved to Redis", key)
+	}
+	return value, nil
+}
+
+// Publish replaces the values cached under their keys and removes the stale keys with Replace.
+// When the cache cannot be updated, every key is invalidated instead, so that readers load
+// the new values from the source rather than keep the old ones until they expire.
+func Publish(ctx context.Context, c Cache, values map[string]interface{}, stale ...string) {
+	keys := make([]string, 0, len(values)+len(stale))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	if err := c.Replace(ctx, values, stale...); err != nil {
+		log.Printf("Error publishing keys %v to Redis: %v", keys, err)
+		Invalidate(ctx, c, append(keys, stale...)...)
+		return
+	}
+	log.Printf("Keys %v published to Redis", keys)
+}
+
+// Invalidate removes the given keys and logs a failure instead of returning it,
+// since a stale entry only lives until its time to live expires.
+func Invalidate(ctx context.Context, c Cache, keys ...string) {
+	if err := c.Delete(ctx, keys...); err != nil {
+		log.Printf("Error deleting keys from Redis: %v", err)
+	}
+}
+
+// KeyPrefix returns the entity of the key of a Redis command, the part before the first colon,
+// e.g. "about" for "about:all". Commands without a key are reported as "none".
+// The cache metrics and the Redis spans both use it, so that they label keys alike.
+func KeyPrefix(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return "none"
+	}
+	key, ok := args[1].(string)
+	if !ok {
+		return "none"
+	}
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
@@ -0,0 +1,46 @@
+// Command openapi prints the OpenAPI document of the API, or checks it against the registered routes.
+//
+// Usage:
+//
+//	go run ./cmd/openapi          # print the document
+//	go run ./cmd/openapi -check   # exit with status 1 when routes and document disagree
+//
+// The routes are registered on a router that is never served, so the handlers have no dependencies
+// and no database or Redis connection is needed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/EkoAgustina/go-ms-portfolio/routes"
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	check := flag.Bool("check", false, "check the document against the registered routes instead of printing it")
+	flag.Parse()
+
+	if !*check {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(routes.Spec()); err != nil {
+			log.Fatalf("Failed to encode the OpenAPI document: %v", err)
+		}
+		return
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	routes.SetupAboutRoutes(router, nil)
+	routes.SetupProjectRoutes(router, nil)
+	routes.SetupContactRoutes(router, nil)
+	routes.SetupOpenAPIRoutes(router)
+
+	if err := routes.CheckSpec(router); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("OpenAPI document matches the routes")
+}
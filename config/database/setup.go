@@ -2,6 +2,8 @@ package database
 
 import (
 	"fmt"
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/config/tracing"
 	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
 	"github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
 	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
@@ -13,12 +15,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// DB is the global database connection instance.
-var DB *gorm.DB
-
 // Connect connects to the PostgreSQL database and performs automatic migrations.
 // It reads configuration from environment variables and retries the connection up to 5 times if it fails.
-// If successful, it logs a confirmation message. If it fails after retries, it logs a fatal error and exits.
+// If successful, it logs a confirmation message and returns the connection, which is passed to the repositories.
+// If it fails after retries, it logs a fatal error and exits.
 //
 // Environment Variables:
 // - DB_HOST: Database server address.
@@ -28,8 +28,8 @@ var DB *gorm.DB
 // - DB_PORT: Port number for the database server.
 //
 // Example:
-//   database.Connect()
-func Connect () {
+//   db := database.Connect()
+func Connect () *gorm.DB {
 	host := utils.LoadEnv("DB_HOST")
 	user := utils.LoadEnv("DB_USER")
 	password := utils.LoadEnv("DB_PASSWORD")
@@ -39,11 +39,12 @@ func Connect () {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		host, user, password, dbname, port)
 
+	var db *gorm.DB
 	var err error
 	maxRetries := 5
 
 	for i := 0; i < maxRetries; i++ {
-		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 		if err == nil {
 			fmt.Println("Database connection established")
 			break
@@ -57,5 +58,28 @@ func Connect () {
 		log.Fatal("Failed to connect to database after retries:", err)
 	}
 
-	DB.AutoMigrate(&aboutmodels.About{}, &projectmodels.Project{}, &contactmodels.Contact{})
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal("Failed to register database metrics:", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal("Failed to register database tracing:", err)
+	}
+
+	// Projects stored before publication statuses existed were public and stay so, while new rows default to drafts
+	backfillStatus := db.Migrator().HasTable(&projectmodels.Project{}) && !db.Migrator().HasColumn(&projectmodels.Project{}, "Status")
+
+	db.AutoMigrate(
+		&aboutmodels.About{}, &aboutmodels.SocialLink{}, &aboutmodels.Skill{}, &aboutmodels.Experience{}, &aboutmodels.Education{}, &aboutmodels.AboutRevision{}, &aboutmodels.AboutTranslation{},
+		&projectmodels.Project{}, &projectmodels.ProjectTranslation{}, &projectmodels.ProjectImage{}, &projectmodels.ProjectSlug{}, &projectmodels.ProjectLink{}, &projectmodels.ProjectLinkCheck{},
+		&projectmodels.ProjectSection{}, &projectmodels.ProjectMetric{},
+		&contactmodels.Contact{},
+	)
+
+	if backfillStatus {
+		err := db.Unscoped().Model(&projectmodels.Project{}).Where("1 = 1").Update("status", projectmodels.StatusPublished).Error
+		if err != nil {
+			log.Fatal("Failed to publish the existing projects:", err)
+		}
+	}
+	return db
 }
\ No newline at end of file
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// redactedValue replaces sensitive values in logged headers and bodies.
+const redactedValue = "[REDACTED]"
+
+// Redactor removes sensitive data from headers and bodies before they are logged.
+type Redactor struct {
+	headers   map[string]bool
+	fields    map[string]bool
+	bodyLimit int
+}
+
+// NewRedactor creates a Redactor from the redaction settings of config.
+func NewRedactor(config Config) *Redactor {
+	r := &Redactor{
+		headers:   make(map[string]bool, len(config.RedactHeaders)),
+		fields:    make(map[string]bool, len(config.RedactFields)),
+		bodyLimit: config.BodyLimit,
+	}
+	for _, header := range config.RedactHeaders {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range config.RedactFields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+// BodyLimit returns the maximum number of body bytes that are logged.
+func (r *Redactor) BodyLimit() int {
+	return r.bodyLimit
+}
+
+// Headers returns the headers as a flat map with the values of sensitive headers redacted.
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	values := make(map[string]string, len(header))
+	for name, value := range header {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			values[name] = redactedValue
+			continue
+		}
+		values[name] = strings.Join(value, ", ")
+	}
+	return values
+}
+
+// Body returns a loggable representation of a request or response body.
+// Only complete JSON bodies within the body limit are logged, with sensitive fields redacted.
+// Other bodies are described by their size so that free text, files and truncated JSON never reach the logs.
+func (r *Redactor) Body(contentType string, body []byte, size int) interface{} {
+	if size == 0 {
+		return nil
+	}
+	if r.bodyLimit <= 0 || size > r.bodyLimit || !strings.Contains(contentType, "json") {
+		return map[string]interface{}{"omitted": true, "size": size}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return map[string]interface{}{"omitted": true, "size": size}
+	}
+	return r.redact(value)
+}
+
+// redact replaces the values of sensitive fields anywhere in a decoded JSON value.
+func (r *Redactor) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = r.redact(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redact(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
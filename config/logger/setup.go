@@ -0,0 +1,117 @@
+// Package logger configures the structured logger used by the service.
+package logger
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDKey is the context key under which the request ID is stored.
+type requestIDKey struct{}
+
+// Config holds the logging configuration.
+type Config struct {
+	Level         slog.Level // Minimum level of logged records
+	Format        string     // Output format, json or text
+	RedactHeaders []string   // Header names whose values are never logged
+	RedactFields  []string   // JSON body field names whose values are never logged
+	BodyLimit     int        // Maximum number of body bytes logged per request or response
+}
+
+// LoadConfig loads the logging configuration from environment variables.
+//
+// Environment Variables:
+// - LOG_LEVEL: debug, info, warn or error (default info).
+// - LOG_FORMAT: json or text (default json).
+// - LOG_REDACT_HEADERS: Comma-separated header names to redact (default authorization, cookie, set-cookie, x-api-key, x-admin-key).
+// - LOG_REDACT_FIELDS: Comma-separated JSON field names to redact (default email, name, message, password, token).
+// - LOG_BODY_LIMIT: Maximum body size in bytes that is logged; 0 disables body logging (default 2048).
+func LoadConfig() Config {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(utils.LoadEnvDefault("LOG_LEVEL", "info"))); err != nil {
+		log.Fatalf("Environment variable LOG_LEVEL is invalid: %v", err)
+	}
+
+	return Config{
+		Level:         level,
+		Format:        utils.LoadEnvDefault("LOG_FORMAT", "json"),
+		RedactHeaders: splitList(utils.LoadEnvDefault("LOG_REDACT_HEADERS", "authorization,cookie,set-cookie,x-api-key,x-admin-key")),
+		RedactFields:  splitList(utils.LoadEnvDefault("LOG_REDACT_FIELDS", "email,name,message,password,token")),
+		BodyLimit:     utils.LoadEnvInt("LOG_BODY_LIMIT", 2048),
+	}
+}
+
+// Setup installs a structured logger as the default slog logger.
+// Records written with the standard log package are routed through the same handler,
+// and every record logged with a request context carries the request ID and trace ID.
+//
+// Example:
+//
+//	logger.Setup(logger.LoadConfig())
+func Setup(config Config) {
+	options := &slog.HandlerOptions{Level: config.Level}
+
+	var handler slog.Handler
+	if config.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, options)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, options)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+}
+
+// WithRequestID returns a copy of ctx that carries the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID and trace context found in the record's context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds the request ID and trace attributes before passing the record to the wrapped handler.
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("requestId", requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("traceId", span.TraceID().String()), slog.String("spanId", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler that keeps adding the request ID.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that keeps adding the request ID.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// splitList splits a comma-separated list into trimmed, lower-case, non-empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
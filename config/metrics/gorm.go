@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startTimeKey is the GORM instance key holding the start time of an operation.
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin records the duration of every GORM operation in DBQueryDuration.
+//
+// Example:
+//
+//	db.Use(metrics.GormPlugin{})
+type GormPlugin struct{}
+
+// Name returns the name of the plugin.
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers the timing callbacks around every GORM operation.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []struct {
+		name string
+		err  error
+	}{
+		{"create before", callback.Create().Before("gorm:create").Register("metrics:before_create", startTimer)},
+		{"create after", callback.Create().After("gorm:create").Register("metrics:after_create", observer("create"))},
+		{"query before", callback.Query().Before("gorm:query").Register("metrics:before_query", startTimer)},
+		{"query after", callback.Query().After("gorm:query").Register("metrics:after_query", observer("query"))},
+		{"update before", callback.Update().Before("gorm:update").Register("metrics:before_update", startTimer)},
+		{"update after", callback.Update().After("gorm:update").Register("metrics:after_update", observer("update"))},
+		{"delete before", callback.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer)},
+		{"delete after", callback.Delete().After("gorm:delete").Register("metrics:after_delete", observer("delete"))},
+		{"row before", callback.Row().Before("gorm:row").Register("metrics:before_row", startTimer)},
+		{"row after", callback.Row().After("gorm:row").Register("metrics:after_row", observer("row"))},
+		{"raw before", callback.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer)},
+		{"raw after", callback.Raw().After("gorm:raw").Register("metrics:after_raw", observer("raw"))},
+	}
+
+	for _, registration := range registrations {
+		if registration.err != nil {
+			return fmt.Errorf("registering %s callback: %w", registration.name, registration.err)
+		}
+	}
+	return nil
+}
+
+// startTimer stores the start time of an operation on the statement.
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(startTimeKey, time.Now())
+}
+
+// observer returns the callback that records the duration of an operation started by startTimer.
+func observer(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		observeQuery(tx, operation)
+	}
+}
+
+// observeQuery records the duration of an operation started by startTimer.
+func observeQuery(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(startTimeKey)
+	if !ok {
+		return
+	}
+	start, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+
+	result := "success"
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		result = "failure"
+	}
+
+	DBQueryDuration.WithLabelValues(operation, tx.Statement.Table, result).Observe(time.Since(start).Seconds())
+}
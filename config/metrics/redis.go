@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisHook counts cache hits, misses and errors per key prefix in CacheOperations.
+// A GET that returns a value is a hit and a GET of a missing key is a miss.
+// Failures of any command are counted as errors.
+//
+// Example:
+//
+//	rdb.AddHook(metrics.RedisHook{})
+type RedisHook struct{}
+
+// BeforeProcess implements redis.Hook.
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterProcess records the result of a single command.
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeCommand(cmd)
+	return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook.
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterProcessPipeline records the result of every command of a pipeline.
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		observeCommand(cmd)
+	}
+	return nil
+}
+
+// observeCommand increments the cache counter that matches the outcome of cmd.
+func observeCommand(cmd redis.Cmder) {
+	err := cmd.Err()
+	switch {
+	case err != nil && err != redis.Nil:
+		CacheOperations.WithLabelValues(cache.KeyPrefix(cmd), "error").Inc()
+	case cmd.Name() != "get":
+		return
+	case err == redis.Nil:
+		CacheOperations.WithLabelValues(cache.KeyPrefix(cmd), "miss").Inc()
+	default:
+		CacheOperations.WithLabelValues(cache.KeyPrefix(cmd), "hit").Inc()
+	}
+}
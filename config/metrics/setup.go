@@ -0,0 +1,105 @@
+// Package metrics defines the Prometheus metrics exposed by the service.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric of the service.
+const namespace = "portfolio"
+
+// Registry holds every metric of the service, including Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts handled HTTP requests by method, route and status code.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes the HTTP request latency by method, route and status code.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// CacheOperations counts Redis cache lookups by key prefix and result (hit, miss or error).
+	CacheOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_operations_total",
+		Help:      "Number of Redis cache operations by key prefix and result.",
+	}, []string{"prefix", "result"})
+
+	// DBQueryDuration observes the duration of GORM operations by operation and table.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query duration in seconds.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "result"})
+
+	// EmailsSent counts email deliveries by result (success or failure).
+	EmailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Number of email deliveries by result.",
+	}, []string{"result"})
+
+	// RetentionRows counts the contact rows touched by the retention job by rule.
+	RetentionRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retention_rows_total",
+		Help:      "Number of contact rows touched by the retention job by rule.",
+	}, []string{"rule"})
+
+	// RepositorySyncs counts the repositories read by the repository sync job by provider and result
+	// (updated, not_modified, not_found, rate_limited or failure).
+	RepositorySyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_syncs_total",
+		Help:      "Number of repositories read by the repository sync job by provider and result.",
+	}, []string{"provider", "result"})
+
+	// JobRuns counts background job runs by job name and result (success or failure).
+	JobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Number of background job runs by job and result.",
+	}, []string{"job", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		CacheOperations,
+		DBQueryDuration,
+		EmailsSent,
+		RetentionRows,
+		RepositorySyncs,
+		JobRuns,
+	)
+}
+
+// Handler returns the HTTP handler that serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Result returns the result label for an operation that failed when err is not nil.
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/config/tracing"
 	"github.com/EkoAgustina/go-ms-portfolio/utils"
 	"github.com/go-redis/redis/v8"
 )
@@ -53,6 +55,9 @@ func SetupRedis(ctx context.Context) (*redis.Client, error) {
 		DB:       config.DB,
 	})
 
+	rdb.AddHook(metrics.RedisHook{})
+	rdb.AddHook(tracing.RedisHook{})
+
 	if err := rdb.Ping(ctx).Err(); err != nil {
 		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
 		
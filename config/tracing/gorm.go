@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey is the GORM instance key holding the span of an operation.
+const spanKey = "tracing:span"
+
+// GormPlugin creates a client span for every GORM operation.
+// Spans are children of the span found in the statement context, so queries
+// must be issued with DB.WithContext to be attached to the request trace.
+//
+// Example:
+//
+//	db.Use(tracing.GormPlugin{})
+type GormPlugin struct{}
+
+// Name returns the name of the plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the span callbacks around every GORM operation.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []struct {
+		name string
+		err  error
+	}{
+		{"create before", callback.Create().Before("gorm:create").Register("tracing:before_create", spanStarter("create"))},
+		{"create after", callback.Create().After("gorm:create").Register("tracing:after_create", endSpan)},
+		{"query before", callback.Query().Before("gorm:query").Register("tracing:before_query", spanStarter("query"))},
+		{"query after", callback.Query().After("gorm:query").Register("tracing:after_query", endSpan)},
+		{"update before", callback.Update().Before("gorm:update").Register("tracing:before_update", spanStarter("update"))},
+		{"update after", callback.Update().After("gorm:update").Register("tracing:after_update", endSpan)},
+		{"delete before", callback.Delete().Before("gorm:delete").Register("tracing:before_delete", spanStarter("delete"))},
+		{"delete after", callback.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan)},
+		{"row before", callback.Row().Before("gorm:row").Register("tracing:before_row", spanStarter("row"))},
+		{"row after", callback.Row().After("gorm:row").Register("tracing:after_row", endSpan)},
+		{"raw before", callback.Raw().Before("gorm:raw").Register("tracing:before_raw", spanStarter("raw"))},
+		{"raw after", callback.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan)},
+	}
+
+	for _, registration := range registrations {
+		if registration.err != nil {
+			return fmt.Errorf("registering %s callback: %w", registration.name, registration.err)
+		}
+	}
+	return nil
+}
+
+// spanStarter returns the callback that starts the span of an operation.
+func spanStarter(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		name := "gorm." + operation
+		if tx.Statement.Table != "" {
+			name += " " + tx.Statement.Table
+		}
+
+		ctx, span := Tracer().Start(tx.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(tx.Statement.Table),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+// endSpan records the statement and outcome of an operation and ends its span.
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
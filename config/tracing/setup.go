@@ -0,0 +1,89 @@
+// Package tracing configures OpenTelemetry tracing for the service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this service.
+const instrumentationName = "github.com/EkoAgustina/go-ms-portfolio"
+
+// Tracer returns the tracer used to create the spans of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// The exporter is selected with environment variables, so local runs need no collector.
+// It returns a function that flushes and stops the exporter on shutdown.
+//
+// Environment Variables:
+// - OTEL_TRACES_EXPORTER: otlp, stdout or none (default none).
+// - OTEL_SERVICE_NAME: Service name reported with every span (default go-ms-portfolio).
+// - OTEL_TRACES_SAMPLER_RATIO: Fraction of new traces that are sampled, between 0 and 1 (default 1).
+// - OTEL_EXPORTER_OTLP_ENDPOINT and related variables configure the otlp exporter.
+//
+// Example:
+//
+//	shutdown, err := tracing.Setup(ctx)
+//	defer shutdown(ctx)
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	// Incoming traceparent headers are honoured even when spans are not exported
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch kind := utils.LoadEnvDefault("OTEL_TRACES_EXPORTER", "none"); kind {
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "none":
+		log.Println("Tracing exporter disabled")
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	ratio, err := strconv.ParseFloat(utils.LoadEnvDefault("OTEL_TRACES_SAMPLER_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_RATIO: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(utils.LoadEnvDefault("OTEL_SERVICE_NAME", "go-ms-portfolio")),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
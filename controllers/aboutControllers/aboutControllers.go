@@ -1,41 +1,51 @@
-// Package aboutcontrollers implements the API endpoints for managing "About" content.
+// Package aboutcontrollers implements the API endpoints for managing the "About" document and its revisions.
 package aboutcontrollers
 
 import (
-    "context"
-    "encoding/json"
-    "log"
+    "errors"
     "net/http"
-    "time"
     "strconv"
+    "strings"
 
-    "github.com/EkoAgustina/go-ms-portfolio/config/database"
+    "github.com/EkoAgustina/go-ms-portfolio/apperrors"
+    "github.com/EkoAgustina/go-ms-portfolio/i18n"
     "github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
-    "github.com/EkoAgustina/go-ms-portfolio/utils"
+    "github.com/EkoAgustina/go-ms-portfolio/repositories"
+    "github.com/EkoAgustina/go-ms-portfolio/services/aboutServices"
 
     "github.com/gin-gonic/gin"
-    "github.com/go-redis/redis/v8"
-    "gorm.io/gorm"
 )
 
-// ctx is the context used for Redis operations.
-var ctx = context.Background()
+// Handler serves the "About" endpoints.
+type Handler struct {
+    service aboutservices.Service
+    locales *i18n.Locales
+}
 
-// CreateAbout handles the HTTP request to create a new "About" entry.
-// It expects a JSON body containing the About model data.
-// On success, it responds with a 201 Created status and the created entry data.
-// On failure (e.g., invalid JSON), it responds with a 400 Bad Request status.
-func CreateAbout(c *gin.Context) {
-    var about aboutmodels.About
-    if err := c.ShouldBindJSON(&about); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "responseCode":    http.StatusBadRequest,
-            "responseMessage": "Invalid request body format",
-        })
-        return
+// NewHandler creates a Handler that uses service for every request and negotiates the locale of reads among locales.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewHandler(service aboutservices.Service, locales *i18n.Locales) *Handler {
+    if service == nil || locales == nil {
+        panic("aboutcontrollers: nil dependency")
     }
+    return &Handler{service: service, locales: locales}
+}
+
+// authorHeader is the request header naming the author of a saved revision.
+const authorHeader = "X-Author"
+
+// defaultAuthor is the author recorded when the request does not send authorHeader.
+const defaultAuthor = "anonymous"
 
-    database.DB.Session(&gorm.Session{PrepareStmt: true}).Create(&about)
+// CreateAbout handles the HTTP request of the legacy route saving the "About" document.
+// It expects a JSON body containing the About model data, which replaces the current document as a new revision.
+// On success, it responds with a 201 Created status and the saved document.
+// On failure, it responds like SaveAbout.
+func (h *Handler) CreateAbout(c *gin.Context) {
+    about, ok := h.save(c)
+    if !ok {
+        return
+    }
 
     c.JSON(http.StatusCreated, gin.H{
         "responseCode": http.StatusCreated,
@@ -43,113 +53,274 @@ func CreateAbout(c *gin.Context) {
     })
 }
 
-// GetAbout handles the HTTP request to retrieve "About" entries.
+// SaveAbout handles the HTTP request to replace the "About" document.
+// It expects a JSON body with the whole profile, saved as a new revision by the author in the X-Author header.
+// On success, it responds with a 200 OK status and the saved document.
+// On failure (e.g., invalid JSON), it responds with a 400 Bad Request status,
+// and with a 422 Unprocessable Entity status when a field, a date range or a translation locale is invalid.
+// If the document cannot be saved, it responds with a 409 Conflict, 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) SaveAbout(c *gin.Context) {
+    about, ok := h.save(c)
+    if !ok {
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         about,
+    })
+}
+
+// GetAbout handles the HTTP request of the legacy route retrieving "About" entries.
+// It responds with the current document as a list of one entry.
+// The locale is negotiated like GetCurrentAbout.
 // It accepts an optional query parameter "id" to fetch a specific entry.
-// It uses Redis for caching; if data is not in the cache, it retrieves it from the database.
+// A non-numeric id is rejected with a 400 Bad Request status.
+// Entries are served from the cache when possible; if Redis cannot be used, they are read from the database.
 // On success, it responds with a 200 OK status and the requested data.
 // If the entry is not found, it responds with a 404 Not Found status.
-// In case of cache errors, it responds with a 500 Internal Server Error status.
-func GetAbout(c *gin.Context) {
-    var about []aboutmodels.About
-    redisTtlStr := utils.LoadEnv("REDIS_CACHE_TTL")
-    redisTtl, err := strconv.Atoi(redisTtlStr)
+func (h *Handler) GetAbout(c *gin.Context) {
+    var entry aboutmodels.About
+    locale := h.locales.Negotiate(c)
+
+    if id := c.Query("id"); id != "" {
+        var ok bool
+        entry, ok = h.findByID(c, id, locale)
+        if !ok {
+            return
+        }
+    } else {
+        var err error
+        entry, err = h.service.Current(c.Request.Context(), locale)
+        if err != nil {
+            _ = c.Error(notFound(err))
+            return
+        }
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         []aboutmodels.About{entry},
+    })
+}
+
+// GetCurrentAbout handles the HTTP request to retrieve the current "About" document.
+// The document is translated into the locale negotiated from the "lang" query parameter or the Accept-Language header.
+// The document is served from the cache when possible; if Redis cannot be used, it is read from the database.
+// On success, it responds with a 200 OK status and the document as data.
+// If no document was saved yet, it responds with a 404 Not Found status.
+func (h *Handler) GetCurrentAbout(c *gin.Context) {
+    about, err := h.service.Current(c.Request.Context(), h.locales.Negotiate(c))
     if err != nil {
-        log.Printf("Error converting REDIS_CACHE_TTL to integer: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "responseCode":    http.StatusInternalServerError,
-            "responseMessage": "Invalid Redis TTL configuration",
-        })
+        _ = c.Error(notFound(err))
         return
     }
 
-    id := c.Query("id")
-    var cacheKey string
-    if id != "" {
-        cacheKey = "about:" + id
-    } else {
-        cacheKey = "about:all"
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         about,
+    })
+}
+
+// ListRevisions handles the HTTP request to list the revisions of the "About" document.
+// On success, it responds with a 200 OK status and the revisions without their snapshots, the most recent first.
+// If the database cannot be read, it responds with a 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) ListRevisions(c *gin.Context) {
+    revisions, err := h.service.Revisions(c.Request.Context())
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
     }
 
-    rdb, _ := c.Get("redis")
-    redisClient := rdb.(*redis.Client)
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         revisions,
+    })
+}
+
+// GetRevision handles the HTTP request to retrieve a revision by the "revision" path parameter.
+// On success, it responds with a 200 OK status and the revision with the document as it was saved.
+// An invalid number is rejected with a 400 Bad Request status, and an unknown revision with a 404 Not Found status.
+func (h *Handler) GetRevision(c *gin.Context) {
+    number, ok := revisionNumber(c, "revision", c.Param("revision"))
+    if !ok {
+        return
+    }
 
-    cachedData, err := redisClient.Get(ctx, cacheKey).Result()
+    revision, err := h.service.Revision(c.Request.Context(), number)
     if err != nil {
-        if err == redis.Nil {
-            log.Printf("Cache miss for key %s", cacheKey)
-
-            // Jika ada parameter id, ambil satu project, jika tidak, ambil semua project
-            if id != "" {
-                if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).First(&about, id).Error; err != nil {
-                    log.Printf("Error fetching from database: %v", err)
-                    c.JSON(http.StatusNotFound, gin.H{
-                        "responseCode":    http.StatusNotFound,
-                        "responseMessage": "Content not found",
-                    })
-                    return
-                }
-            } else {
-                // Ambil semua project jika tidak ada id
-                if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).Find(&about).Error; err != nil {
-                    log.Printf("Error fetching from database: %v", err)
-                    c.JSON(http.StatusInternalServerError, gin.H{
-                        "responseCode":    http.StatusInternalServerError,
-                        "responseMessage": "Error retrieving data",
-                    })
-                    return
-                }
-            }
-            
-            if len(about) == 0 {
-                c.JSON(http.StatusNotFound, gin.H{
-                    "responseCode":    http.StatusNotFound,
-                    "responseMessage": "No content found",
-                })
-                return
-            }
-
-            response := gin.H{
-                "responseCode": http.StatusOK,
-                "data":         about,
-            }
-
-            jsonData, err := json.Marshal(response)
-            if err != nil {
-                log.Printf("Error marshaling JSON: %v", err)
-                c.JSON(http.StatusInternalServerError, gin.H{
-                    "responseCode":    http.StatusInternalServerError,
-                    "responseMessage": "Internal Server Error",
-                })
-                return
-            }
-
-            err = redisClient.Set(ctx, cacheKey, jsonData, time.Duration(redisTtl)*time.Second).Err()
-            if err != nil {
-                log.Printf("Error saving to Redis: %v", err)
-            } else {
-                log.Printf("Key %s saved to Redis", cacheKey)
-            }
-
-            c.JSON(http.StatusOK, response)
-        } else {
-            log.Printf("Error accessing Redis: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{
-                "responseCode":    http.StatusInternalServerError,
-                "responseMessage": "Error accessing cache",
-            })
-            return
-        }
-    } else {
-        log.Printf("Cache hit for key %s", cacheKey)
-        cachedResponse := gin.H{}
-        if err := json.Unmarshal([]byte(cachedData), &cachedResponse); err != nil {
-            log.Printf("Error unmarshaling JSON from Redis: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{
-                "responseCode":    http.StatusInternalServerError,
-                "responseMessage": "Error processing cached data",
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         revision,
+    })
+}
+
+// DiffRevisions handles the HTTP request to compare the revisions given by the "from" and "to" query parameters.
+// On success, it responds with a 200 OK status and the list of changes made from one revision to the other.
+// An invalid number is rejected with a 400 Bad Request status, and an unknown revision with a 404 Not Found status.
+func (h *Handler) DiffRevisions(c *gin.Context) {
+    from, ok := revisionNumber(c, "from", c.Query("from"))
+    if !ok {
+        return
+    }
+    to, ok := revisionNumber(c, "to", c.Query("to"))
+    if !ok {
+        return
+    }
+
+    changes, err := h.service.Diff(c.Request.Context(), from, to)
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         changes,
+    })
+}
+
+// RollbackRevision handles the HTTP request to restore the revision given by the "revision" path parameter.
+// The restored document is saved as a new revision whose author is taken from the X-Author header.
+// On success, it responds with a 201 Created status and the new revision.
+// An invalid number is rejected with a 400 Bad Request status, and an unknown revision with a 404 Not Found status.
+func (h *Handler) RollbackRevision(c *gin.Context) {
+    number, ok := revisionNumber(c, "revision", c.Param("revision"))
+    if !ok {
+        return
+    }
+
+    revision, err := h.service.Rollback(c.Request.Context(), number, author(c))
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusCreated, gin.H{
+        "responseCode": http.StatusCreated,
+        "data":         revision,
+    })
+}
+
+// GetMissingTranslations handles the HTTP request to report the locales lacking a translation of the "About" document.
+// On success, it responds with a 200 OK status and one entry per locale with the untranslated fields.
+// If the database cannot be read, it responds with a 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) GetMissingTranslations(c *gin.Context) {
+    missing, err := h.service.MissingTranslations(c.Request.Context())
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         missing,
+    })
+}
+
+// save binds the request body and saves it as the current document.
+// On failure, it adds the error to the context and returns false.
+func (h *Handler) save(c *gin.Context) (aboutmodels.About, bool) {
+    var about aboutmodels.About
+    if err := c.ShouldBindJSON(&about); err != nil {
+        _ = c.Error(apperrors.Binding(err))
+        return aboutmodels.About{}, false
+    }
+
+    fields := dateRangeErrors(about)
+    fields = append(fields, h.locales.Check("translations", translationLocales(about))...)
+    if len(fields) > 0 {
+        _ = c.Error(apperrors.Validation(fields))
+        return aboutmodels.About{}, false
+    }
+
+    if _, err := h.service.Save(c.Request.Context(), &about, author(c)); err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return aboutmodels.About{}, false
+    }
+    return about, true
+}
+
+// author returns the author of a revision saved by the request.
+func author(c *gin.Context) string {
+    if author := strings.TrimSpace(c.GetHeader(authorHeader)); author != "" {
+        return author
+    }
+    return defaultAuthor
+}
+
+// revisionNumber parses the revision number sent in the parameter name.
+// On failure, it adds the error to the context and returns false.
+func revisionNumber(c *gin.Context, name string, value string) (int, bool) {
+    number, err := strconv.Atoi(value)
+    if err != nil || number < 1 {
+        _ = c.Error(apperrors.InvalidParameter(name, name+" must be a positive integer"))
+        return 0, false
+    }
+    return number, true
+}
+
+// notFound converts the error of reading the current document, reporting a missing document
+// with the message of the legacy route.
+func notFound(err error) *apperrors.Error {
+    if errors.Is(err, repositories.ErrNotFound) {
+        return apperrors.NotFound("No content found")
+    }
+    return apperrors.FromDatabase(err)
+}
+
+// findByID parses id and loads the matching entry in locale.
+// On failure, it adds the error to the context and returns false.
+func (h *Handler) findByID(c *gin.Context, id string, locale string) (aboutmodels.About, bool) {
+    aboutID, err := strconv.ParseUint(id, 10, 32)
+    if err != nil {
+        _ = c.Error(apperrors.InvalidParameter("id", "id must be a positive integer"))
+        return aboutmodels.About{}, false
+    }
+
+    entry, err := h.service.FindByID(c.Request.Context(), uint(aboutID), locale)
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return aboutmodels.About{}, false
+    }
+    return entry, true
+}
+
+// translationLocales returns the locales of the translations of about, in the order they are sent.
+func translationLocales(about aboutmodels.About) []string {
+    locales := make([]string, len(about.Translations))
+    for i, translation := range about.Translations {
+        locales[i] = translation.Locale
+    }
+    return locales
+}
+
+// dateRangeErrors reports the experience and education entries whose end date is before their start date.
+// Dates are YYYY-MM strings, so they compare chronologically as strings.
+func dateRangeErrors(about aboutmodels.About) []apperrors.FieldError {
+    var fields []apperrors.FieldError
+    check := func(field string, start string, end *string) {
+        if end != nil && *end != "" && *end < start {
+            fields = append(fields, apperrors.FieldError{
+                Field:   field + ".endDate",
+                Code:    "gtefield",
+                Message: "must not be before startDate",
             })
-            return
         }
-        c.JSON(http.StatusOK, cachedResponse)
     }
+
+    for i, experience := range about.Experience {
+        check("experience["+strconv.Itoa(i)+"]", experience.StartDate, experience.EndDate)
+    }
+    for i, education := range about.Education {
+        check("education["+strconv.Itoa(i)+"]", education.StartDate, education.EndDate)
+    }
+    return fields
 }
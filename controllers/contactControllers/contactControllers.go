@@ -2,56 +2,57 @@ package contactcontrollers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
-	"time"
 	"strconv"
 
-	"github.com/EkoAgustina/go-ms-portfolio/config/database"
-	"github.com/EkoAgustina/go-ms-portfolio/hooks"
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/jobs"
 	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
-	"github.com/EkoAgustina/go-ms-portfolio/utils"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/services/contactServices"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
-	"gorm.io/gorm"
 )
 
-// ctx is the context used for Redis operations.
-var ctx = context.Background()
+// RetentionPreviewer applies the contact retention policy. *jobs.RetentionJob implements it.
+type RetentionPreviewer interface {
+	Apply(ctx context.Context, dryRun bool) (jobs.RetentionReport, error)
+}
+
+// Handler serves the "Contact" endpoints.
+type Handler struct {
+	service   contactservices.Service
+	retention RetentionPreviewer
+}
+
+// NewHandler creates a Handler that uses service for every request and retention for the retention preview.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewHandler(service contactservices.Service, retention RetentionPreviewer) *Handler {
+	if service == nil || retention == nil {
+		panic("contactcontrollers: nil dependency")
+	}
+	return &Handler{service: service, retention: retention}
+}
 
 // CreateContact handles the HTTP request to create a new "Contact" entry.
 // It expects a JSON body containing the Contact model data.
 // On success, it responds with a 201 Created status and the created entry data.
 // On failure (e.g., invalid JSON), it responds with a 400 Bad Request status.
-// Additionally, it sends an email notification with the contact details.
-func CreateContact(c *gin.Context) {
+// If the entry cannot be saved, it responds with a 409 Conflict, 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+// Once the entry is saved, it sends an email notification with the contact details.
+func (h *Handler) CreateContact(c *gin.Context) {
 	var contact contactmodels.Contact
-	emailTarget := utils.LoadEnv("EMAIL_TARGET")
-	
+
 	// Bind JSON to contact struct
 	if err := c.ShouldBindJSON(&contact); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"responseCode":    http.StatusBadRequest,
-			"responseMessage": "Invalid request body format",
-		})
+		_ = c.Error(apperrors.Binding(err))
 		return
 	}
 
-	database.DB.Session(&gorm.Session{PrepareStmt: true}).Create(&contact)
-
-	emailMsg := fmt.Sprintf(`Hi,
-
-You received a new message from a Portfolio Website visitor:
-
-Name: %s
-Email: %s
-Message: %s
-
-Thank you.`, contact.Name, contact.Email, contact.Message)
-
-	hooks.SendEmail(emailTarget, contact.Subject, emailMsg)
+	if err := h.service.Create(c.Request.Context(), &contact); err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"responseCode": http.StatusCreated,
@@ -61,111 +62,125 @@ Thank you.`, contact.Name, contact.Email, contact.Message)
 
 // GetContactMe handles the HTTP request to retrieve "Contact" entries.
 // It accepts an optional query parameter "id" to fetch a specific entry.
-// It uses Redis for caching; if data is not in the cache, it retrieves it from the database.
+// A non-numeric id is rejected with a 400 Bad Request status.
+// Entries are served from the cache when possible; if Redis cannot be used, they are read from the database.
 // On success, it responds with a 200 OK status and the requested data.
 // If the entry is not found, it responds with a 404 Not Found status.
-// In case of cache errors, it responds with a 500 Internal Server Error status.
-func GetContactMe(c *gin.Context) {
+func (h *Handler) GetContactMe(c *gin.Context) {
 	var contact []contactmodels.Contact
-	redisTtlStr := utils.LoadEnv("REDIS_CACHE_TTL")
-	redisTtl, err := strconv.Atoi(redisTtlStr)
+
+	if id := c.Query("id"); id != "" {
+		entry, ok := h.findByID(c, id)
+		if !ok {
+			return
+		}
+		contact = []contactmodels.Contact{entry}
+	} else {
+		var err error
+		contact, err = h.service.FindAll(c.Request.Context())
+		if err != nil {
+			_ = c.Error(apperrors.FromDatabase(err))
+			return
+		}
+	}
+
+	if len(contact) == 0 {
+		_ = c.Error(apperrors.NotFound("No content found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         contact,
+	})
+}
+
+// UpdateContactStatus handles the HTTP request to change the triage status of a "Contact" entry.
+// It expects the contact ID as a path parameter and a JSON body with the new status (new, spam or archived).
+// The status decides which retention rule applies to the message.
+// On success, it responds with a 200 OK status and the updated entry data.
+// If the entry is not found, it responds with a 404 Not Found status.
+func (h *Handler) UpdateContactStatus(c *gin.Context) {
+	var body contactmodels.ContactStatus
+	if err := c.ShouldBindJSON(&body); err != nil {
+		_ = c.Error(apperrors.Binding(err))
+		return
+	}
+
+	contactID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		log.Printf("Error converting REDIS_CACHE_TTL to integer: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"responseCode":    http.StatusInternalServerError,
-			"responseMessage": "Invalid Redis TTL configuration",
-		})
+		_ = c.Error(apperrors.InvalidParameter("id", "id must be a positive integer"))
 		return
 	}
-	
-	id := c.Query("id")
-	var cacheKey string
-	if id != "" {
-		cacheKey = "contact:" + id
-	} else {
-		cacheKey = "contact:all"
+
+	contact, err := h.service.UpdateStatus(c.Request.Context(), uint(contactID), body.Status)
+	if err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
 	}
 
-	rdb, _ := c.Get("redis")
-	redisClient := rdb.(*redis.Client)
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         contact,
+	})
+}
 
-	cachedData, err := redisClient.Get(ctx, cacheKey).Result()
+// GetRetentionReport handles the HTTP request to retrieve the metrics of the contact retention job.
+// It reports the active policy, the number of runs and the rows touched by each rule.
+// On success, it responds with a 200 OK status and the report as data.
+func (h *Handler) GetRetentionReport(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         jobs.RetentionStats(),
+	})
+}
 
+// DryRunRetention handles the HTTP request to preview the contact retention policy.
+// It counts the rows every rule would touch right now without changing the database.
+// On success, it responds with a 200 OK status and the counts as data.
+// If the database cannot be read, it responds with a 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) DryRunRetention(c *gin.Context) {
+	report, err := h.retention.Apply(c.Request.Context(), true)
 	if err != nil {
-		if err == redis.Nil {
-			log.Printf("Cache miss for key %s", cacheKey)
-
-			// Fetch contact(s)
-			if id != "" {
-				if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).First(&contact, id).Error; err != nil {
-					log.Printf("Error fetching from database: %v", err)
-					c.JSON(http.StatusNotFound, gin.H{
-						"responseCode":    http.StatusNotFound,
-						"responseMessage": "Content not found",
-					})
-					return
-				}
-			} else {
-				if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).Find(&contact).Error; err != nil {
-					log.Printf("Error fetching from database: %v", err)
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"responseCode":    http.StatusInternalServerError,
-						"responseMessage": "Error retrieving data",
-					})
-					return
-				}
-			}
-
-			if len(contact) == 0 {
-                c.JSON(http.StatusNotFound, gin.H{
-                    "responseCode":    http.StatusNotFound,
-                    "responseMessage": "No content found",
-                })
-                return
-            }
-
-			response := gin.H{
-				"responseCode": http.StatusOK,
-				"data":         contact,
-			}
-
-			jsonData, err := json.Marshal(response)
-			if err != nil {
-				log.Printf("Error marshaling JSON: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"responseCode":    http.StatusInternalServerError,
-					"responseMessage": "Internal Server Error",
-				})
-				return
-			}
-
-			err = redisClient.Set(ctx, cacheKey, jsonData, time.Duration(redisTtl)*time.Second).Err()
-			if err != nil {
-				log.Printf("Error saving to Redis: %v", err)
-			} else {
-				log.Printf("Key %s saved to Redis", cacheKey)
-			}
-
-			c.JSON(http.StatusOK, response)
-		} else {
-			log.Printf("Error accessing Redis: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"responseCode":    http.StatusInternalServerError,
-				"responseMessage": "Error accessing cache",
-			})
-			return
-		}
-	} else {
-		log.Printf("Cache hit for key %s", cacheKey)
-		cachedResponse := gin.H{}
-		if err := json.Unmarshal([]byte(cachedData), &cachedResponse); err != nil {
-			log.Printf("Error unmarshaling JSON from Redis: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"responseCode":    http.StatusInternalServerError,
-				"responseMessage": "Error processing cached data",
-			})
-			return
-		}
-		c.JSON(http.StatusOK, cachedResponse)
+		_ = c.Error(apperrors.FromDatabase(repositories.Translate(err)))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         report,
+	})
+}
+
+// GetContactByID handles the HTTP request to retrieve a single "Contact" entry by the "id" path parameter.
+// On success, it responds with a 200 OK status and the entry as data.
+// A non-numeric id is rejected with a 400 Bad Request status, and an unknown id with a 404 Not Found status.
+func (h *Handler) GetContactByID(c *gin.Context) {
+	entry, ok := h.findByID(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":		 entry,
+	})
+}
+
+// findByID parses id and loads the matching entry.
+// On failure, it adds the error to the context and returns false.
+func (h *Handler) findByID(c *gin.Context, id string) (contactmodels.Contact, bool) {
+	contactID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		_ = c.Error(apperrors.InvalidParameter("id", "id must be a positive integer"))
+		return contactmodels.Contact{}, false
+	}
+
+	entry, err := h.service.FindByID(c.Request.Context(), uint(contactID))
+	if err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return contactmodels.Contact{}, false
 	}
+	return entry, true
 }
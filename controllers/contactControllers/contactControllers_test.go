@@ -0,0 +1,118 @@
+package contactcontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/jobs"
+	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/services/contactServices"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeService serves the handler from memory. Methods a test does not use panic through the nil interface.
+type fakeService struct {
+	contactservices.Service
+	err     error                  // Returned by every method
+	created *contactmodels.Contact // Entry passed to Create
+}
+
+func (s *fakeService) Create(ctx context.Context, contact *contactmodels.Contact) error {
+	s.created = contact
+	if s.err != nil {
+		return s.err
+	}
+	contact.ID = 1
+	return nil
+}
+
+func (s *fakeService) FindByID(ctx context.Context, id uint) (contactmodels.Contact, error) {
+	if s.err != nil {
+		return contactmodels.Contact{}, s.err
+	}
+	return contactmodels.Contact{Name: "Visitor"}, nil
+}
+
+// fakeRetention reports an empty retention run.
+type fakeRetention struct{}
+
+func (fakeRetention) Apply(ctx context.Context, dryRun bool) (jobs.RetentionReport, error) {
+	return jobs.RetentionReport{}, nil
+}
+
+func TestCreateContact(t *testing.T) {
+	const valid = `{"name":"Visitor","email":"visitor@example.com","message":"Hi"}`
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		code   apperrors.Code
+	}{
+		{"created", valid, nil, http.StatusCreated, ""},
+		{"invalid JSON", `{"name":`, nil, http.StatusBadRequest, apperrors.CodeInvalidBody},
+		{"invalid email", `{"name":"Visitor","email":"visitor@example.com\nFrom x"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed},
+		{"conflict", valid, fmt.Errorf("create contact: %w", repositories.ErrConflict), http.StatusConflict, apperrors.CodeConflict},
+		{"database down", valid, fmt.Errorf("create contact: %w", repositories.ErrUnavailable), http.StatusServiceUnavailable, apperrors.CodeDatabaseUnavailable},
+		{"database error", valid, fmt.Errorf("create contact: pq: disk full"), http.StatusInternalServerError, apperrors.CodeDatabaseError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(middlewares.ErrorHandler())
+			router.POST("/contacts", NewHandler(&fakeService{err: tt.err}, fakeRetention{}).CreateContact)
+
+			request := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			var problem apperrors.Problem
+			_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+			if recorder.Code != tt.status || problem.Code != tt.code {
+				t.Errorf("response = %d %s, want %d with code %q", recorder.Code, recorder.Body, tt.status, tt.code)
+			}
+			if strings.Contains(recorder.Body.String(), "pq:") {
+				t.Errorf("body leaks the cause: %s", recorder.Body)
+			}
+		})
+	}
+}
+
+func TestGetContactMe(t *testing.T) {
+	tests := []struct {
+		target string
+		err    error
+		status int
+		code   apperrors.Code
+	}{
+		{"/contacts?id=7", nil, http.StatusOK, ""},
+		{"/contacts?id=x", nil, http.StatusBadRequest, apperrors.CodeInvalidParameter},
+		{"/contacts?id=7", repositories.ErrNotFound, http.StatusNotFound, apperrors.CodeNotFound},
+		{"/contacts?id=7", repositories.ErrUnavailable, http.StatusServiceUnavailable, apperrors.CodeDatabaseUnavailable},
+	}
+	for _, tt := range tests {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(middlewares.ErrorHandler())
+		router.GET("/contacts", NewHandler(&fakeService{err: tt.err}, fakeRetention{}).GetContactMe)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		var problem apperrors.Problem
+		_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+		if recorder.Code != tt.status || problem.Code != tt.code {
+			t.Errorf("GET %s with error %v = %d %s, want %d with code %q", tt.target, tt.err, recorder.Code, recorder.Body, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package contactcontrollers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/contactRepositories"
+	"github.com/gin-gonic/gin"
+)
+
+// exportBatchSize is the number of rows loaded from the database per batch during an export.
+const exportBatchSize = 500
+
+// contactExporter writes contact messages in one export format.
+type contactExporter interface {
+	// header is written once before the first row.
+	header() error
+	// write writes a single contact message.
+	write(contact contactmodels.Contact) error
+	// flush writes any buffered data to the response.
+	flush() error
+}
+
+// exportFormats maps the supported export formats to their content type and file extension.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":   {"text/csv; charset=utf-8", "csv"},
+	"jsonl": {"application/x-ndjson; charset=utf-8", "jsonl"},
+	"mbox":  {"application/mbox", "mbox"},
+}
+
+// ExportContacts handles the HTTP request to export "Contact" entries as a CSV, JSON Lines or mbox file.
+// The optional "format" (default csv), "from", "to" and "status" query parameters select the format and the messages,
+// which are streamed from the database in batches.
+// On success, it responds with a 200 OK status and the file as an attachment.
+// On invalid parameters, it responds with a 400 Bad Request status.
+func (h *Handler) ExportContacts(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	exportFormat, ok := exportFormats[format]
+	if !ok {
+		_ = c.Error(apperrors.InvalidParameter("format", "format must be one of csv, jsonl or mbox"))
+		return
+	}
+
+	var filter contactrepositories.Filter
+
+	if from := c.Query("from"); from != "" {
+		fromTime, err := parseExportDate(from, false)
+		if err != nil {
+			_ = c.Error(apperrors.InvalidParameter("from", "from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"))
+			return
+		}
+		filter.From = fromTime
+	}
+
+	if to := c.Query("to"); to != "" {
+		toTime, err := parseExportDate(to, true)
+		if err != nil {
+			_ = c.Error(apperrors.InvalidParameter("to", "to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"))
+			return
+		}
+		filter.To = toTime
+	}
+
+	if status := c.Query("status"); status != "" {
+		if status != contactmodels.StatusNew && status != contactmodels.StatusSpam && status != contactmodels.StatusArchived {
+			_ = c.Error(apperrors.InvalidParameter("status", "status must be one of new, spam or archived"))
+			return
+		}
+		filter.Status = status
+	}
+
+	filename := fmt.Sprintf("contacts-%s.%s", time.Now().Format("20060102-150405"), exportFormat.extension)
+	c.Header("Content-Type", exportFormat.contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	exporter := newContactExporter(format, c.Writer)
+	if err := exporter.header(); err != nil {
+		log.Printf("Error writing export header: %v", err)
+		return
+	}
+
+	var rows int
+	err := h.service.Export(c.Request.Context(), filter, exportBatchSize, func(batch []contactmodels.Contact) error {
+		for _, contact := range batch {
+			if err := exporter.write(contact); err != nil {
+				return err
+			}
+		}
+		rows += len(batch)
+		if err := exporter.flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil && !c.Writer.Written() {
+		// Nothing was sent yet, so the failure can still be reported as a problem
+		c.Writer.Header().Del("Content-Disposition")
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
+	}
+	if err != nil {
+		// The status line is already sent, so the client only sees a truncated file.
+		log.Printf("Error exporting contacts after %d rows: %v", rows, err)
+		return
+	}
+
+	if err := exporter.flush(); err != nil {
+		log.Printf("Error writing export: %v", err)
+		return
+	}
+	log.Printf("Exported %d contacts as %s", rows, format)
+}
+
+// parseExportDate parses a date filter of the export endpoint.
+// A plain date is interpreted in the server's time zone; when endOfDay is true,
+// it returns the start of the following day so the whole date is included.
+func parseExportDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// newContactExporter returns the exporter for a format validated against exportFormats.
+func newContactExporter(format string, w io.Writer) contactExporter {
+	switch format {
+	case "jsonl":
+		return &jsonlExporter{w: bufio.NewWriter(w)}
+	case "mbox":
+		return &mboxExporter{w: bufio.NewWriter(w)}
+	default:
+		return &csvExporter{w: csv.NewWriter(w)}
+	}
+}
+
+// csvExporter writes contact messages as CSV with a header row.
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) header() error {
+	return e.w.Write([]string{"id", "createdAt", "status", "name", "email", "subject", "message"})
+}
+
+func (e *csvExporter) write(contact contactmodels.Contact) error {
+	return e.w.Write([]string{
+		strconv.FormatUint(uint64(contact.ID), 10),
+		contact.CreatedAt.Format(time.RFC3339),
+		contact.Status,
+		csvSafe(contact.Name),
+		csvSafe(contact.Email),
+		csvSafe(contact.Subject),
+		csvSafe(contact.Message),
+	})
+}
+
+func (e *csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvSafe prevents spreadsheet applications from evaluating visitor input as a formula
+// by prefixing cells that start with a formula character with a single quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// jsonlExporter writes one JSON object per contact message and line.
+type jsonlExporter struct {
+	w *bufio.Writer
+}
+
+func (e *jsonlExporter) header() error {
+	return nil
+}
+
+func (e *jsonlExporter) write(contact contactmodels.Contact) error {
+	line, err := json.Marshal(contact)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(line); err != nil {
+		return err
+	}
+	return e.w.WriteByte('\n')
+}
+
+func (e *jsonlExporter) flush() error {
+	return e.w.Flush()
+}
+
+// mboxExporter writes contact messages as an mboxrd mailbox that mail clients can import.
+type mboxExporter struct {
+	w *bufio.Writer
+}
+
+func (e *mboxExporter) header() error {
+	return nil
+}
+
+func (e *mboxExporter) write(contact contactmodels.Contact) error {
+	from := mail.Address{Name: contact.Name, Address: contact.Email}
+
+	fmt.Fprintf(e.w, "From %s %s\n", mboxSender(contact.Email), contact.CreatedAt.UTC().Format(time.ANSIC))
+	fmt.Fprintf(e.w, "From: %s\n", from.String())
+	fmt.Fprintf(e.w, "Subject: %s\n", mime.QEncoding.Encode("utf-8", contact.Subject))
+	fmt.Fprintf(e.w, "Date: %s\n", contact.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(e.w, "Message-ID: <contact-%d@go-ms-portfolio>\n", contact.ID)
+	fmt.Fprintf(e.w, "X-Contact-Status: %s\n", contact.Status)
+	fmt.Fprintf(e.w, "MIME-Version: 1.0\n")
+	fmt.Fprintf(e.w, "Content-Type: text/plain; charset=utf-8\n")
+	fmt.Fprintf(e.w, "Content-Transfer-Encoding: 8bit\n\n")
+
+	body := strings.ReplaceAll(contact.Message, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		// mboxrd quoting: any line matching ^>*From gets one more '>'
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		e.w.WriteString(line)
+		e.w.WriteByte('\n')
+	}
+
+	_, err := e.w.WriteString("\n")
+	return err
+}
+
+func (e *mboxExporter) flush() error {
+	return e.w.Flush()
+}
+
+// mboxSender returns the sender written on the separator line of a message from email.
+// Addresses stored before emails were validated may break the line, so only plain addresses are written.
+func mboxSender(email string) string {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || strings.ContainsFunc(email, unicode.IsSpace) {
+		return "MAILER-DAEMON"
+	}
+	return email
+}
@@ -0,0 +1,119 @@
+package contactcontrollers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Visitor", "Visitor"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1 555", "'+1 555"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+	for _, tt := range tests {
+		if got := csvSafe(tt.value); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCSVExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := newContactExporter("csv", &buf)
+	contact := contactmodels.Contact{Name: "=cmd|' /C calc'!A0", Email: "visitor@example.com", Subject: "Hi", Message: "-1"}
+	if err := exporter.header(); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.write(contact); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 2 || records[1][3] != "'=cmd|' /C calc'!A0" || records[1][6] != "'-1" {
+		t.Errorf("records = %q, want the name and message prefixed with a quote", records)
+	}
+}
+
+// exportMbox writes contacts with the mbox exporter and returns the mailbox.
+func exportMbox(t *testing.T, contacts ...contactmodels.Contact) string {
+	t.Helper()
+	var buf bytes.Buffer
+	exporter := newContactExporter("mbox", &buf)
+	for _, contact := range contacts {
+		if err := exporter.write(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exporter.flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestMboxExporterQuotesFromLines(t *testing.T) {
+	contact := contactmodels.Contact{
+		Email:   "visitor@example.com",
+		Subject: "Hi",
+		Message: "Hello\r\nFrom here\n>From there\n>>From everywhere\nFromage\n From indented",
+	}
+	contact.CreatedAt = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	mbox := exportMbox(t, contact)
+
+	wantBody := "Hello\n>From here\n>>From there\n>>>From everywhere\nFromage\n From indented\n\n"
+	if !strings.HasSuffix(mbox, wantBody) {
+		t.Errorf("mbox = %q, want the body %q", mbox, wantBody)
+	}
+	// Only the separator starts a line with "From "
+	if got := strings.Count("\n"+mbox, "\nFrom "); got != 1 {
+		t.Errorf("mbox has %d separator lines, want 1:\n%s", got, mbox)
+	}
+	if !strings.HasPrefix(mbox, "From visitor@example.com Wed May  1 10:00:00 2024\n") {
+		t.Errorf("mbox starts with %q, want the separator line", strings.SplitN(mbox, "\n", 2)[0])
+	}
+}
+
+func TestMboxSender(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"visitor@example.com", "visitor@example.com"},
+		{"", "MAILER-DAEMON"},
+		{"not an address", "MAILER-DAEMON"},
+		{"Visitor <visitor@example.com>", "MAILER-DAEMON"},
+		{"visitor@example.com\nFrom evil@example.com", "MAILER-DAEMON"},
+		{"\"a b\"@example.com", "MAILER-DAEMON"},
+	}
+	for _, tt := range tests {
+		if got := mboxSender(tt.email); got != tt.want {
+			t.Errorf("mboxSender(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+
+	// A stored address that would break the separator line is replaced
+	contact := contactmodels.Contact{Email: "x@example.com\nFrom evil@example.com Thu Jan  1 00:00:00 1970", Message: "Hi"}
+	if got := strings.Count("\n"+exportMbox(t, contact), "\nFrom "); got != 1 {
+		t.Errorf("mbox has %d separator lines, want 1", got)
+	}
+}
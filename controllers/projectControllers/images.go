@@ -0,0 +1,235 @@
+package projectcontrollers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/images"
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/projectRepositories"
+	"github.com/EkoAgustina/go-ms-portfolio/services/projectServices"
+)
+
+// multipartOverhead is the room left in the request body for the multipart boundaries and the text fields.
+const multipartOverhead = 64 << 10
+
+// Maximum lengths of the text fields of gallery images in characters.
+const (
+	maxImageAlt     = 255
+	maxImageCaption = 1000
+)
+
+// AddProjectImage handles the HTTP request to add an image to the gallery of the "Project" entry given by the "id"
+// path parameter.
+// It expects a multipart form with the file in the "image" field and the optional "alt", "caption" and "cover" fields.
+// The image is stored without its metadata, together with its resized variants, at the end of the gallery.
+// On success, it responds with a 201 Created status and the updated entry data with its gallery.
+// It responds with a 413 Request Entity Too Large status when the file exceeds the size limit,
+// a 415 Unsupported Media Type status when it is not a JPEG, PNG, GIF or WebP image,
+// a 422 Unprocessable Entity status when the image cannot be used or a field is invalid,
+// a 404 Not Found status when the entry does not exist and a 503 Service Unavailable status when the storage fails.
+func (h *Handler) AddProjectImage(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.limits.MaxBytes+multipartOverhead)
+	file, header, err := c.Request.FormFile("image")
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		_ = c.Error(h.tooLarge(err))
+		return
+	case errors.Is(err, http.ErrMissingFile):
+		_ = c.Error(apperrors.Validation([]apperrors.FieldError{{Field: "image", Code: "required", Message: "is required"}}))
+		return
+	case err != nil:
+		_ = c.Error(apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Request body must be a multipart form"))
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.limits.MaxBytes {
+		_ = c.Error(h.tooLarge(nil))
+		return
+	}
+
+	image := projectmodels.ProjectImage{
+		Alt:     strings.TrimSpace(c.PostForm("alt")),
+		Caption: strings.TrimSpace(c.PostForm("caption")),
+	}
+	if image.Alt == "" {
+		image.Alt = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	}
+	var fields []apperrors.FieldError
+	if utf8.RuneCountInString(image.Alt) > maxImageAlt {
+		fields = append(fields, apperrors.FieldError{
+			Field: "alt", Code: "max", Message: fmt.Sprintf("must be at most %d characters long", maxImageAlt),
+		})
+	}
+	if utf8.RuneCountInString(image.Caption) > maxImageCaption {
+		fields = append(fields, apperrors.FieldError{
+			Field: "caption", Code: "max", Message: fmt.Sprintf("must be at most %d characters long", maxImageCaption),
+		})
+	}
+	if cover := c.PostForm("cover"); cover != "" {
+		if image.Cover, err = strconv.ParseBool(cover); err != nil {
+			fields = append(fields, apperrors.FieldError{Field: "cover", Code: "boolean", Message: "must be true or false"})
+		}
+	}
+	if len(fields) > 0 {
+		_ = c.Error(apperrors.Validation(fields))
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = c.Error(apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Error reading the uploaded file"))
+		return
+	}
+
+	project, err := h.service.AddImage(c.Request.Context(), projectID, data, image)
+	if err != nil {
+		_ = c.Error(uploadError(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"responseCode": http.StatusCreated,
+		"data":         project,
+	})
+}
+
+// UpdateProjectImage handles the HTTP request to change the alt text, caption or cover flag of a gallery image
+// given by the "id" and "imageId" path parameters. It expects a JSON body with the fields to change.
+// On success, it responds with a 200 OK status and the updated entry data with its gallery.
+// Invalid IDs are rejected with a 400 Bad Request status, invalid fields with a 422 Unprocessable Entity status,
+// and an unknown entry or image with a 404 Not Found status.
+func (h *Handler) UpdateProjectImage(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseID(c, "imageId")
+	if !ok {
+		return
+	}
+
+	var update projectmodels.ProjectImageUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		_ = c.Error(apperrors.Binding(err))
+		return
+	}
+
+	project, err := h.service.UpdateImage(c.Request.Context(), projectID, imageID, update)
+	if err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         project,
+	})
+}
+
+// ReorderProjectImages handles the HTTP request to change the display order of the gallery of a "Project" entry
+// by the "id" path parameter. It expects a JSON body listing the IDs of every image of the gallery in the new order.
+// On success, it responds with a 200 OK status and the updated entry data with its gallery.
+// It responds with a 422 Unprocessable Entity status when the IDs are not every image of the gallery exactly once,
+// and with a 404 Not Found status when the entry does not exist.
+func (h *Handler) ReorderProjectImages(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var order projectmodels.ProjectImageOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		_ = c.Error(apperrors.Binding(err))
+		return
+	}
+
+	project, err := h.service.ReorderImages(c.Request.Context(), projectID, order.IDs)
+	if errors.Is(err, projectrepositories.ErrImageOrder) {
+		_ = c.Error(apperrors.Validation([]apperrors.FieldError{{
+			Field: "ids", Code: "order", Message: "must list every image of the project exactly once",
+		}}))
+		return
+	}
+	if err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"responseCode": http.StatusOK,
+		"data":         project,
+	})
+}
+
+// DeleteProjectImage handles the HTTP request to remove an image of the gallery, given by the "id" and "imageId"
+// path parameters, and delete its files from the storage.
+// On success, it responds with a 204 No Content status.
+// Invalid IDs are rejected with a 400 Bad Request status, and an unknown entry or image with a 404 Not Found status.
+func (h *Handler) DeleteProjectImage(c *gin.Context) {
+	projectID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseID(c, "imageId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteImage(c.Request.Context(), projectID, imageID); err != nil {
+		_ = c.Error(apperrors.FromDatabase(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parseID parses the path parameter name as the ID of an entry.
+// On failure, it adds the error to the context and returns false.
+func parseID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		_ = c.Error(apperrors.InvalidParameter(name, name+" must be a positive integer"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// tooLarge creates the 413 Request Entity Too Large error of an upload exceeding the size limit.
+func (h *Handler) tooLarge(err error) *apperrors.Error {
+	return apperrors.Wrap(err, http.StatusRequestEntityTooLarge, apperrors.CodeBodyTooLarge,
+		fmt.Sprintf("Image must not exceed %d bytes", h.limits.MaxBytes))
+}
+
+// uploadError converts an error of the image upload into an *apperrors.Error.
+func uploadError(err error) *apperrors.Error {
+	switch {
+	case errors.Is(err, images.ErrUnsupportedType):
+		return apperrors.Wrap(err, http.StatusUnsupportedMediaType, apperrors.CodeUnsupportedMedia,
+			"Image must be a JPEG, PNG, GIF or WebP file")
+	case errors.Is(err, images.ErrInvalidImage):
+		return apperrors.Validation([]apperrors.FieldError{{Field: "image", Code: "image", Message: "must be a valid image"}})
+	case errors.Is(err, images.ErrTooManyPixels):
+		return apperrors.Validation([]apperrors.FieldError{{Field: "image", Code: "max", Message: "has too many pixels"}})
+	case errors.Is(err, projectservices.ErrStorage):
+		return apperrors.Wrap(err, http.StatusServiceUnavailable, apperrors.CodeStorageUnavailable,
+			"Image storage is unavailable, try again later")
+	default:
+		return apperrors.FromDatabase(err)
+	}
+}
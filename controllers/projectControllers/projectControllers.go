@@ -1,164 +1,374 @@
 package projectcontrollers
 
 import (
-    "context"
-    "encoding/json"
-    "log"
+    "errors"
     "net/http"
-    "time"
+    "net/url"
+    "path"
     "strconv"
+    "strings"
+    "time"
 
     "github.com/gin-gonic/gin"
-    "github.com/go-redis/redis/v8"
-    "gorm.io/gorm"
 
-    "github.com/EkoAgustina/go-ms-portfolio/config/database"
+    "github.com/EkoAgustina/go-ms-portfolio/apperrors"
+    "github.com/EkoAgustina/go-ms-portfolio/i18n"
+    "github.com/EkoAgustina/go-ms-portfolio/images"
     "github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
-    "github.com/EkoAgustina/go-ms-portfolio/utils"
+    "github.com/EkoAgustina/go-ms-portfolio/repositories/projectRepositories"
+    "github.com/EkoAgustina/go-ms-portfolio/services/projectServices"
 )
 
-// ctx is the context used for Redis operations.
-var ctx = context.Background()
+// DraftsParameter is the query parameter of the read endpoints that also returns draft and archived entries.
+// Routes must require the admin key when it is true, see middlewares.ValidateAdminKeyWhen.
+const DraftsParameter = "drafts"
+
+// Handler serves the "Project" endpoints.
+type Handler struct {
+    service projectservices.Service
+    locales *i18n.Locales
+    limits  images.Limits
+}
+
+// NewHandler creates a Handler that uses service for every request, negotiates the locale of reads among locales
+// and rejects uploaded images larger than limits.MaxBytes.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewHandler(service projectservices.Service, locales *i18n.Locales, limits images.Limits) *Handler {
+    if service == nil || locales == nil {
+        panic("projectcontrollers: nil dependency")
+    }
+    return &Handler{service: service, locales: locales, limits: limits}
+}
 
 // CreateProject handles the HTTP request to create a new "Project" entry.
-// It expects a JSON body containing the Project model data.
+// It expects a JSON body containing the Project model data. The entry is always created as a draft.
+// A unique slug is generated from projectTitle, transliterated into ASCII.
 // On success, it responds with a 201 Created status and the created entry data.
-// On failure (e.g., invalid JSON), it responds with a 400 Bad Request status.
-func CreateProject(c *gin.Context) {
+// On failure (e.g., invalid JSON), it responds with a 400 Bad Request status,
+// and with a 422 Unprocessable Entity status when a field, a date range or a translation locale is invalid,
+// or when a status other than draft or a publishAt is given.
+// If the entry cannot be saved, it responds with a 409 Conflict, 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) CreateProject(c *gin.Context) {
     var project projectmodels.Project
-	var maxProjectDescription = 270
     if err := c.ShouldBindJSON(&project); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "responseCode":    http.StatusBadRequest,
-            "responseMessage": "Invalid request body format",
-        })
+        _ = c.Error(apperrors.Binding(err))
         return
     }
 
-	if len(project.ProjectDescription) > maxProjectDescription {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "responseCode":    http.StatusBadRequest,
-            "responseMessage": "Bad Request: projectDescription length should not exceed " + strconv.Itoa(maxProjectDescription) + " characters",
-        })
+    fields := h.contentErrors(project)
+    fields = append(fields, creationStatusErrors(project)...)
+    if len(fields) > 0 {
+        _ = c.Error(apperrors.Validation(fields))
+        return
+    }
+
+    if err := h.service.Create(c.Request.Context(), &project); err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
         return
     }
 
-    database.DB.Session(&gorm.Session{PrepareStmt: true}).Create(&project)
-    
     c.JSON(http.StatusCreated, gin.H{
         "responseCode": http.StatusCreated,
         "data":         project,
     })
 }
 
+// UpdateProject handles the HTTP request to replace the content of a "Project" entry.
+// It expects the entry ID as a path parameter and a JSON body like CreateProject.
+// The status, display order and gallery of the entry are left unchanged.
+// When the title changes, the entry gets a new slug and requests for the previous one are redirected to it.
+// On success, it responds with a 200 OK status and the updated entry data.
+// It responds with a 422 Unprocessable Entity status when a field, a date range or a translation locale is invalid,
+// and with a 404 Not Found status when the entry does not exist.
+func (h *Handler) UpdateProject(c *gin.Context) {
+    projectID, ok := parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var project projectmodels.Project
+    if err := c.ShouldBindJSON(&project); err != nil {
+        _ = c.Error(apperrors.Binding(err))
+        return
+    }
+
+    if fields := h.contentErrors(project); len(fields) > 0 {
+        _ = c.Error(apperrors.Validation(fields))
+        return
+    }
+
+    project.ID = projectID
+    if err := h.service.Update(c.Request.Context(), &project); err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         project,
+    })
+}
+
 // GetProject handles the HTTP request to retrieve "Project" entries.
 // It accepts an optional query parameter "id" to fetch a specific entry.
-// It uses Redis for caching; if data is not in the cache, it retrieves it from the database.
+// A non-numeric id is rejected with a 400 Bad Request status.
+// Entries are translated into the locale negotiated from the "lang" query parameter or the Accept-Language header.
+// Only published entries are returned, unless the admin-only "drafts" query parameter is true, and only featured
+// entries when the "featured" query parameter is true. Admin reads flag broken links in brokenLinks.
+// Lists follow the manual display order and only hold the summary of the entries, even when fetched by "id".
+// Public entries are served from the cache when possible; if Redis cannot be used, they are read from the database.
 // On success, it responds with a 200 OK status and the requested data.
 // If the entry is not found, it responds with a 404 Not Found status.
-// In case of cache errors, it responds with a 500 Internal Server Error status.
-func GetProject(c *gin.Context) {
+func (h *Handler) GetProject(c *gin.Context) {
     var project []projectmodels.Project
-    redisTtlStr := utils.LoadEnv("REDIS_CACHE_TTL")
-    redisTtl, err := strconv.Atoi(redisTtlStr)
-    if err != nil {
-        log.Printf("Error converting REDIS_CACHE_TTL to integer: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "responseCode":    http.StatusInternalServerError,
-            "responseMessage": "Invalid Redis TTL configuration",
+    locale := h.locales.Negotiate(c)
+
+    if id := c.Query("id"); id != "" {
+        entry, ok := h.findByID(c, id, locale, drafts(c))
+        if !ok {
+            return
+        }
+        // Like the rest of the list, the entry is a summary; the write-up and metrics are only sent by GetProjectByID
+        entry.Sections, entry.Metrics = nil, nil
+        project = []projectmodels.Project{entry}
+    } else {
+        var err error
+        project, err = h.service.FindAll(c.Request.Context(), locale, projectrepositories.Filter{
+            Drafts:   drafts(c),
+            Featured: queryFlag(c, "featured"),
         })
+        if err != nil {
+            _ = c.Error(apperrors.FromDatabase(err))
+            return
+        }
+    }
+
+    if len(project) == 0 {
+        _ = c.Error(apperrors.NotFound("No content found"))
         return
     }
 
-    id := c.Query("id")
-    var cacheKey string
-    if id != "" {
-        cacheKey = "project:" + id
-    } else {
-        cacheKey = "project:all"
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         project,
+    })
+}
+
+// GetProjectByID handles the HTTP request to retrieve a single "Project" entry by the "id" path parameter,
+// which holds either the numeric ID or the slug of the entry.
+// The entry is translated like in GetProject; an entry that is not published needs the "drafts" query parameter.
+// On success, it responds with a 200 OK status and the entry data, including its sections and metrics.
+// A previous slug of a renamed entry is answered with a 301 Moved Permanently status redirecting to its current slug.
+// An invalid id is rejected with a 400 Bad Request status, and an unknown one with a 404 Not Found status.
+func (h *Handler) GetProjectByID(c *gin.Context) {
+    id := c.Param("id")
+    if strings.Trim(id, "0123456789") != "" {
+        h.getProjectBySlug(c, id)
+        return
     }
 
-    rdb, _ := c.Get("redis")
-    redisClient := rdb.(*redis.Client)
+    entry, ok := h.findByID(c, id, h.locales.Negotiate(c), drafts(c))
+    if !ok {
+        return
+    }
 
-    cachedData, err := redisClient.Get(ctx, cacheKey).Result()
-    if err != nil {
-        if err == redis.Nil {
-            log.Printf("Cache miss for key %s", cacheKey)
-
-            // Fetch project(s)
-            if id != "" {
-                if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).First(&project, id).Error; err != nil {
-                    log.Printf("Error fetching from database: %v", err)
-                    c.JSON(http.StatusNotFound, gin.H{
-                        "responseCode":    http.StatusNotFound,
-                        "responseMessage": "Content not found",
-                    })
-                    return
-                }
-            } else {
-                if err := database.DB.Session(&gorm.Session{PrepareStmt: true}).Find(&project).Error; err != nil {
-                    log.Printf("Error fetching from database: %v", err)
-                    c.JSON(http.StatusInternalServerError, gin.H{
-                        "responseCode":    http.StatusInternalServerError,
-                        "responseMessage": "Error retrieving data",
-                    })
-                    return
-                }
-            }
-
-            if len(project) == 0 {
-                c.JSON(http.StatusNotFound, gin.H{
-                    "responseCode":    http.StatusNotFound,
-                    "responseMessage": "No content found",
-                })
-                return
-            }
-
-            response := gin.H{
-                "responseCode": http.StatusOK,
-                "data":         project,
-            }
-
-            jsonData, err := json.Marshal(response)
-            if err != nil {
-                log.Printf("Error marshaling JSON: %v", err)
-                c.JSON(http.StatusInternalServerError, gin.H{
-                    "responseCode":    http.StatusInternalServerError,
-                    "responseMessage": "Internal Server Error",
-                })
-                return
-            }
-
-            err = redisClient.Set(ctx, cacheKey, jsonData, time.Duration(redisTtl)*time.Second).Err()
-            if err != nil {
-                log.Printf("Error saving to Redis: %v", err)
-            } else {
-                log.Printf("Key %s saved to Redis", cacheKey)
-            }
-
-            c.JSON(http.StatusOK, response)
-
-        } else {
-            log.Printf("Error accessing Redis: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{
-                "responseCode":    http.StatusInternalServerError,
-                "responseMessage": "Error accessing cache",
-            })
-            return
-        }
-    } else {
-        log.Printf("Cache hit for key %s", cacheKey)
-        cachedResponse := gin.H{}
-        if err := json.Unmarshal([]byte(cachedData), &cachedResponse); err != nil {
-            log.Printf("Error unmarshaling JSON from Redis: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{
-                "responseCode":    http.StatusInternalServerError,
-                "responseMessage": "Error processing cached data",
-            })
-            return
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         entry,
+    })
+}
+
+// getProjectBySlug responds to GetProjectByID with the entry identified by slug.
+func (h *Handler) getProjectBySlug(c *gin.Context, slug string) {
+    entry, err := h.service.FindBySlug(c.Request.Context(), slug, h.locales.Negotiate(c), drafts(c))
+    var moved *projectservices.MovedError
+    if errors.As(err, &moved) {
+        // The query string is kept, so that the locale and drafts parameters still apply
+        location := path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(moved.Slug))
+        if c.Request.URL.RawQuery != "" {
+            location += "?" + c.Request.URL.RawQuery
         }
+        c.Redirect(http.StatusMovedPermanently, location)
+        return
+    }
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         entry,
+    })
+}
+
+// GetMissingTranslations handles the HTTP request to report the "Project" entries lacking a translation.
+// On success, it responds with a 200 OK status and one entry per project and locale with the untranslated fields.
+// If the database cannot be read, it responds with a 503 Service Unavailable
+// or 500 Internal Server Error status depending on the database error.
+func (h *Handler) GetMissingTranslations(c *gin.Context) {
+    missing, err := h.service.MissingTranslations(c.Request.Context())
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         missing,
+    })
+}
+
+// GetProjectLinkChecks handles the HTTP request to retrieve the status history of the links of a "Project" entry.
+// It expects the entry ID as a path parameter.
+// On success, it responds with a 200 OK status and every check of the link checker, the most recent first.
+// It responds with a 404 Not Found status when the entry does not exist.
+func (h *Handler) GetProjectLinkChecks(c *gin.Context) {
+    projectID, ok := parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    checks, err := h.service.LinkChecks(c.Request.Context(), projectID)
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         checks,
+    })
+}
+
+// UpdateProjectStatus handles the HTTP request to change the publication status of a "Project" entry.
+// It expects the entry ID as a path parameter and a JSON body with the new status (draft, published or archived)
+// and, for drafts, an optional publishAt time at which the scheduler publishes the entry.
+// On success, it responds with a 200 OK status and the updated entry data.
+// It responds with a 422 Unprocessable Entity status when publishAt is given for another status,
+// and with a 404 Not Found status when the entry does not exist.
+func (h *Handler) UpdateProjectStatus(c *gin.Context) {
+    projectID, ok := parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var status projectmodels.ProjectStatus
+    if err := c.ShouldBindJSON(&status); err != nil {
+        _ = c.Error(apperrors.Binding(err))
+        return
+    }
+    if fields := publishAtErrors(status.Status, status.PublishAt); len(fields) > 0 {
+        _ = c.Error(apperrors.Validation(fields))
+        return
+    }
+
+    project, err := h.service.UpdateStatus(c.Request.Context(), projectID, status)
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         project,
+    })
+}
 
-        c.JSON(http.StatusOK, cachedResponse)
+// ReorderProjects handles the HTTP request to curate the display order of "Project" entries.
+// It expects a JSON body listing the IDs of the entries to show first and, optionally, the IDs of the featured entries.
+// Every entry is renumbered in one transaction.
+// On success, it responds with a 200 OK status and every entry, drafts included, in the new order.
+// It responds with a 422 Unprocessable Entity status when an ID is repeated or does not exist.
+func (h *Handler) ReorderProjects(c *gin.Context) {
+    var order projectmodels.ProjectOrder
+    if err := c.ShouldBindJSON(&order); err != nil {
+        _ = c.Error(apperrors.Binding(err))
+        return
+    }
+
+    projects, err := h.service.Reorder(c.Request.Context(), order)
+    if errors.Is(err, projectrepositories.ErrProjectOrder) {
+        _ = c.Error(apperrors.Validation([]apperrors.FieldError{{
+            Field: "ids", Code: "exists", Message: "must only list existing projects, in ids and featured",
+        }}))
+        return
+    }
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "responseCode": http.StatusOK,
+        "data":         projects,
+    })
+}
+
+// drafts reports whether the "drafts" query parameter asks for entries that are not published.
+func drafts(c *gin.Context) bool {
+    return queryFlag(c, DraftsParameter)
+}
+
+// queryFlag reports whether the boolean query parameter name is true.
+// Invalid values are rejected against the OpenAPI document before the handler runs.
+func queryFlag(c *gin.Context, name string) bool {
+    enabled, _ := strconv.ParseBool(c.Query(name))
+    return enabled
+}
+
+// contentErrors checks the fields of project that the binding rules cannot: the locales of its translations
+// and the order of its dates.
+func (h *Handler) contentErrors(project projectmodels.Project) []apperrors.FieldError {
+    locales := make([]string, len(project.Translations))
+    for i, translation := range project.Translations {
+        locales[i] = translation.Locale
+    }
+    fields := h.locales.Check("translations", locales)
+
+    // Dates are YYYY-MM strings, so they compare chronologically as strings
+    if project.StartDate != nil && project.EndDate != nil && *project.EndDate < *project.StartDate {
+        fields = append(fields, apperrors.FieldError{Field: "endDate", Code: "gtefield", Message: "must not be before startDate"})
+    }
+    return fields
+}
+
+// creationStatusErrors checks that a new entry is a draft without a publication time. Creating only requires
+// the API key, so entries are published or scheduled with UpdateProjectStatus, which requires the admin key.
+func creationStatusErrors(project projectmodels.Project) []apperrors.FieldError {
+    var fields []apperrors.FieldError
+    if project.Status != "" && project.Status != projectmodels.StatusDraft {
+        fields = append(fields, apperrors.FieldError{Field: "status", Code: "eq", Message: "must be draft; publish the project by changing its status"})
+    }
+    if project.PublishAt != nil {
+        fields = append(fields, apperrors.FieldError{Field: "publishAt", Code: "excluded", Message: "is set by changing the status of the project"})
+    }
+    return fields
+}
+
+// publishAtErrors checks that a publication time is only given to drafts, the only status the scheduler changes.
+// An empty status is the draft default of new entries.
+func publishAtErrors(status string, publishAt *time.Time) []apperrors.FieldError {
+    if publishAt == nil || status == "" || status == projectmodels.StatusDraft {
+        return nil
+    }
+    return []apperrors.FieldError{{Field: "publishAt", Code: "excluded_unless", Message: "is only allowed for drafts"}}
+}
+
+// findByID parses id and loads the matching entry in locale, which may be unpublished when drafts is true.
+// On failure, it adds the error to the context and returns false.
+func (h *Handler) findByID(c *gin.Context, id string, locale string, drafts bool) (projectmodels.Project, bool) {
+    projectID, err := strconv.ParseUint(id, 10, 32)
+    if err != nil {
+        _ = c.Error(apperrors.InvalidParameter("id", "id must be a positive integer"))
+        return projectmodels.Project{}, false
+    }
+
+    entry, err := h.service.FindByID(c.Request.Context(), uint(projectID), locale, drafts)
+    if err != nil {
+        _ = c.Error(apperrors.FromDatabase(err))
+        return projectmodels.Project{}, false
     }
+    return entry, true
 }
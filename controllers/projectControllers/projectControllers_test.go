@@ -0,0 +1,244 @@
+package projectcontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
+	"github.com/EkoAgustina/go-ms-portfolio/images"
+	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/services/projectServices"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeService serves the handler from memory. Methods a test does not use panic through the nil interface.
+type fakeService struct {
+	projectservices.Service
+	err     error                        // Returned by every method
+	created *projectmodels.Project       // Entry passed to Create
+	drafts  bool                         // drafts flag passed to FindByID
+	status  *projectmodels.ProjectStatus // Status passed to UpdateStatus
+}
+
+func (s *fakeService) Create(ctx context.Context, project *projectmodels.Project) error {
+	s.created = project
+	if s.err != nil {
+		return s.err
+	}
+	project.ID = 1
+	return nil
+}
+
+func (s *fakeService) FindByID(ctx context.Context, id uint, locale string, drafts bool) (projectmodels.Project, error) {
+	s.drafts = drafts
+	if s.err != nil {
+		return projectmodels.Project{}, s.err
+	}
+	return projectmodels.Project{
+		ProjectTitle: "Portfolio",
+		Sections:     []projectmodels.ProjectSection{{Type: "problem", Body: "Slow pages"}},
+		Metrics:      []projectmodels.ProjectMetric{{Label: "Load time", Value: "-40%"}},
+	}, nil
+}
+
+func (s *fakeService) UpdateStatus(ctx context.Context, id uint, status projectmodels.ProjectStatus) (projectmodels.Project, error) {
+	s.status = &status
+	if s.err != nil {
+		return projectmodels.Project{}, s.err
+	}
+	return projectmodels.Project{Status: status.Status}, nil
+}
+
+// newTestHandler returns a Handler serving service in English and Indonesian.
+func newTestHandler(t *testing.T, service projectservices.Service) *Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	locales, err := i18n.NewLocales([]string{"en", "id"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHandler(service, locales, images.Limits{})
+}
+
+func TestCreateProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		code   apperrors.Code
+		field  string
+	}{
+		{"created", `{"projectTitle":"Portfolio"}`, nil, http.StatusCreated, "", ""},
+		{"invalid JSON", `{"projectTitle":`, nil, http.StatusBadRequest, apperrors.CodeInvalidBody, ""},
+		{"description too long", `{"projectTitle":"Portfolio","projectDescription":"` + strings.Repeat("a", 271) + `"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed, "projectDescription"},
+		{"published", `{"projectTitle":"Portfolio","status":"published"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed, "status"},
+		{"archived", `{"projectTitle":"Portfolio","status":"archived"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed, "status"},
+		{"scheduled", `{"projectTitle":"Portfolio","publishAt":"2030-01-01T00:00:00Z"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed, "publishAt"},
+		{"conflict", `{"projectTitle":"Portfolio"}`, fmt.Errorf("create project: %w", repositories.ErrConflict), http.StatusConflict, apperrors.CodeConflict, ""},
+		{"database error", `{"projectTitle":"Portfolio"}`, fmt.Errorf("create project: pq: disk full"), http.StatusInternalServerError, apperrors.CodeDatabaseError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeService{err: tt.err}
+			router := gin.New()
+			router.Use(middlewares.ErrorHandler())
+			router.POST("/projects", newTestHandler(t, service).CreateProject)
+
+			request := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			var problem apperrors.Problem
+			_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+			if recorder.Code != tt.status || problem.Code != tt.code {
+				t.Fatalf("response = %d %s, want %d with code %q", recorder.Code, recorder.Body, tt.status, tt.code)
+			}
+			if tt.field != "" {
+				if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field {
+					t.Errorf("errors = %+v, want one on %s", problem.Errors, tt.field)
+				}
+				if service.created != nil {
+					t.Error("Create was called, want the entry rejected before reaching the service")
+				}
+			}
+			if strings.Contains(recorder.Body.String(), "pq:") {
+				t.Errorf("body leaks the cause: %s", recorder.Body)
+			}
+		})
+	}
+}
+
+func TestGetProjectByID(t *testing.T) {
+	tests := []struct {
+		target string
+		err    error
+		status int
+		code   apperrors.Code
+		drafts bool
+	}{
+		{"/projects/7", nil, http.StatusOK, "", false},
+		{"/projects/7?drafts=true", nil, http.StatusOK, "", true},
+		{"/projects/7", fmt.Errorf("find project: %w", repositories.ErrNotFound), http.StatusNotFound, apperrors.CodeNotFound, false},
+		{"/projects/7", fmt.Errorf("find project: %w", repositories.ErrUnavailable), http.StatusServiceUnavailable, apperrors.CodeDatabaseUnavailable, false},
+		{"/projects/7", fmt.Errorf("find project: pq: relation does not exist"), http.StatusInternalServerError, apperrors.CodeDatabaseError, false},
+	}
+	for _, tt := range tests {
+		service := &fakeService{err: tt.err}
+		router := gin.New()
+		router.Use(middlewares.ErrorHandler())
+		router.GET("/projects/:id", newTestHandler(t, service).GetProjectByID)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		var problem apperrors.Problem
+		_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+		if recorder.Code != tt.status || problem.Code != tt.code || service.drafts != tt.drafts {
+			t.Errorf("GET %s with error %v = %d %s and drafts %t, want %d with code %q and drafts %t",
+				tt.target, tt.err, recorder.Code, recorder.Body, service.drafts, tt.status, tt.code, tt.drafts)
+		}
+		if strings.Contains(recorder.Body.String(), "pq:") {
+			t.Errorf("body leaks the cause: %s", recorder.Body)
+		}
+	}
+}
+
+func TestGetProjectByIDQuery(t *testing.T) {
+	tests := []struct {
+		target string
+		err    error
+		status int
+		code   apperrors.Code
+	}{
+		{"/projects?id=7", nil, http.StatusOK, ""},
+		{"/projects?id=x", nil, http.StatusBadRequest, apperrors.CodeInvalidParameter},
+		{"/projects?id=7", fmt.Errorf("find project: %w", repositories.ErrNotFound), http.StatusNotFound, apperrors.CodeNotFound},
+		{"/projects?id=7", fmt.Errorf("find project: %w", repositories.ErrUnavailable), http.StatusServiceUnavailable, apperrors.CodeDatabaseUnavailable},
+		{"/projects?id=7", fmt.Errorf("find project: pq: relation does not exist"), http.StatusInternalServerError, apperrors.CodeDatabaseError},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		router.Use(middlewares.ErrorHandler())
+		router.GET("/projects", newTestHandler(t, &fakeService{err: tt.err}).GetProject)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		var problem apperrors.Problem
+		_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+		if recorder.Code != tt.status || problem.Code != tt.code {
+			t.Errorf("GET %s with error %v = %d %s, want %d with code %q", tt.target, tt.err, recorder.Code, recorder.Body, tt.status, tt.code)
+		}
+		if strings.Contains(recorder.Body.String(), "pq:") {
+			t.Errorf("body leaks the cause: %s", recorder.Body)
+		}
+	}
+
+	// The entry has the summary shape of the list, without the write-up and metrics
+	router := gin.New()
+	router.GET("/projects", newTestHandler(t, &fakeService{}).GetProject)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/projects?id=7", nil))
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || len(response.Data) != 1 {
+		t.Fatalf("GET /projects?id=7 = %d %s, want a list of one entry", recorder.Code, recorder.Body)
+	}
+	for _, key := range []string{"sections", "metrics"} {
+		if _, ok := response.Data[0][key]; ok {
+			t.Errorf("entry = %v, want no %s", response.Data[0], key)
+		}
+	}
+}
+
+func TestUpdateProjectStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		err    error
+		status int
+		code   apperrors.Code
+	}{
+		{"scheduled draft", "/projects/7/status", `{"status":"draft","publishAt":"2030-01-01T00:00:00Z"}`, nil, http.StatusOK, ""},
+		{"published", "/projects/7/status", `{"status":"published"}`, nil, http.StatusOK, ""},
+		{"scheduled publication", "/projects/7/status", `{"status":"published","publishAt":"2030-01-01T00:00:00Z"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed},
+		{"unknown status", "/projects/7/status", `{"status":"deleted"}`, nil, http.StatusUnprocessableEntity, apperrors.CodeValidationFailed},
+		{"invalid id", "/projects/x/status", `{"status":"published"}`, nil, http.StatusBadRequest, apperrors.CodeInvalidParameter},
+		{"missing entry", "/projects/7/status", `{"status":"published"}`, repositories.ErrNotFound, http.StatusNotFound, apperrors.CodeNotFound},
+		{"database down", "/projects/7/status", `{"status":"published"}`, repositories.ErrUnavailable, http.StatusServiceUnavailable, apperrors.CodeDatabaseUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeService{err: tt.err}
+			router := gin.New()
+			router.Use(middlewares.ErrorHandler())
+			router.PATCH("/projects/:id/status", newTestHandler(t, service).UpdateProjectStatus)
+
+			request := httptest.NewRequest(http.MethodPatch, tt.target, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			var problem apperrors.Problem
+			_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+			if recorder.Code != tt.status || problem.Code != tt.code {
+				t.Errorf("response = %d %s, want %d with code %q", recorder.Code, recorder.Body, tt.status, tt.code)
+			}
+			if tt.code == apperrors.CodeValidationFailed && service.status != nil {
+				t.Error("UpdateStatus was called, want the status rejected before reaching the service")
+			}
+		})
+	}
+}
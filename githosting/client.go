@@ -0,0 +1,196 @@
+package githosting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseBytes bounds the body of API responses read by the Client.
+const maxResponseBytes = 1 << 20
+
+// Client reads repository metadata from the providers of its Config.
+// It is safe for concurrent use.
+type Client struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu     sync.Mutex
+	limits map[string]time.Time // Providers whose rate limit is exhausted, until the limit resets
+}
+
+// NewClient returns a Client for config.
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		limits: map[string]time.Time{},
+	}
+}
+
+// Fetch returns the metadata of repository. When etag is the ETag of the previous Fetch and the
+// repository did not change, it returns ErrNotModified; such requests do not count against the
+// rate limit of GitHub. It returns ErrNotFound for unknown repositories and a *RateLimitError,
+// without calling the provider, while its rate limit is exhausted.
+func (c *Client) Fetch(ctx context.Context, repository Repository, etag string) (Metadata, error) {
+	c.mu.Lock()
+	reset, limited := c.limits[repository.Provider]
+	c.mu.Unlock()
+	if limited && c.now().Before(reset) {
+		return Metadata{}, &RateLimitError{Provider: repository.Provider, Reset: reset}
+	}
+
+	switch repository.Provider {
+	case GitHub:
+		return c.fetchGitHub(ctx, repository, etag)
+	case GitLab:
+		return c.fetchGitLab(ctx, repository, etag)
+	}
+	return Metadata{}, fmt.Errorf("unknown provider %q", repository.Provider)
+}
+
+// fetchGitHub reads repository from the GitHub REST API.
+func (c *Client) fetchGitHub(ctx context.Context, repository Repository, etag string) (Metadata, error) {
+	var body struct {
+		Stars    int        `json:"stargazers_count"`
+		Forks    int        `json:"forks_count"`
+		Language string     `json:"language"`
+		Topics   []string   `json:"topics"`
+		PushedAt *time.Time `json:"pushed_at"`
+	}
+	owner, name, _ := strings.Cut(repository.Path, "/")
+	endpoint := strings.TrimSuffix(c.config.GitHubAPI, "/") + "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+	etag, err := c.get(ctx, GitHub, endpoint, etag, &body)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Metadata{
+		Stars: body.Stars, Forks: body.Forks, Language: body.Language, Topics: body.Topics, PushedAt: body.PushedAt, ETag: etag,
+	}, nil
+}
+
+// fetchGitLab reads repository from the GitLab REST API. GitLab reports languages separately,
+// as percentages; the largest one is the primary language.
+func (c *Client) fetchGitLab(ctx context.Context, repository Repository, etag string) (Metadata, error) {
+	var body struct {
+		ID             int        `json:"id"`
+		Stars          int        `json:"star_count"`
+		Forks          int        `json:"forks_count"`
+		Topics         []string   `json:"topics"`
+		TagList        []string   `json:"tag_list"` // Topics before GitLab 14.0
+		LastActivityAt *time.Time `json:"last_activity_at"`
+	}
+	base := strings.TrimSuffix(c.config.GitLabAPI, "/") + "/projects/"
+	etag, err := c.get(ctx, GitLab, base+url.PathEscape(repository.Path), etag, &body)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var languages map[string]float64
+	if _, err := c.get(ctx, GitLab, base+strconv.Itoa(body.ID)+"/languages", "", &languages); err != nil {
+		return Metadata{}, err
+	}
+	var language string
+	for name, share := range languages {
+		if language == "" || share > languages[language] || (share == languages[language] && name < language) {
+			language = name
+		}
+	}
+
+	topics := body.Topics
+	if topics == nil {
+		topics = body.TagList
+	}
+	return Metadata{
+		Stars: body.Stars, Forks: body.Forks, Language: language, Topics: topics, PushedAt: body.LastActivityAt, ETag: etag,
+	}, nil
+}
+
+// get sends a GET request for endpoint to provider, conditional on etag when it is not empty,
+// decodes the JSON response into v and returns its ETag. It records the rate limit reported by the response.
+func (c *Client) get(ctx context.Context, provider string, endpoint string, etag string, v interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	switch provider {
+	case GitHub:
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if c.config.GitHubToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.config.GitHubToken)
+		}
+	case GitLab:
+		req.Header.Set("Accept", "application/json")
+		if c.config.GitLabToken != "" {
+			req.Header.Set("PRIVATE-TOKEN", c.config.GitLabToken)
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if reset, limited := c.rateLimit(resp); limited {
+		c.mu.Lock()
+		c.limits[provider] = reset
+		c.mu.Unlock()
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			return "", &RateLimitError{Provider: provider, Reset: reset}
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return etag, ErrNotModified
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, endpoint)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return "", fmt.Errorf("%s: unexpected status %d for %s", provider, resp.StatusCode, endpoint)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v); err != nil {
+		return "", fmt.Errorf("%s: decoding %s: %w", provider, endpoint, err)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// rateLimit reports whether resp exhausts the rate limit of its provider and when the limit resets.
+// GitHub sends X-RateLimit-* headers, GitLab RateLimit-* headers, and both may send Retry-After
+// with a 429 or 403 response.
+func (c *Client) rateLimit(resp *http.Response) (time.Time, bool) {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return c.now().Add(time.Duration(seconds) * time.Second), true
+	}
+
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	reset := resp.Header.Get("X-RateLimit-Reset")
+	if remaining == "" {
+		remaining, reset = resp.Header.Get("RateLimit-Remaining"), resp.Header.Get("RateLimit-Reset")
+	}
+	if remaining != "0" {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			// Without any hint, the provider is left alone for a minute
+			return c.now().Add(time.Minute), true
+		}
+		return time.Time{}, false
+	}
+	if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		return time.Unix(unix, 0), true
+	}
+	return c.now().Add(time.Minute), true
+}
@@ -0,0 +1,145 @@
+// Package githosting reads the metadata of repositories, such as their stars and language, from the
+// REST APIs of Git hosting providers: GitHub and GitLab.
+//
+// The base URLs of the APIs are configurable, for GitHub Enterprise, self-managed GitLab instances
+// or a local fake server in tests. Responses are validated with ETags, and a provider is not called
+// again once its rate limit is exhausted, until the limit resets.
+package githosting
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+)
+
+// Providers of repositories.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Repository identifies a repository on a provider.
+type Repository struct {
+	Provider string // GitHub or GitLab
+	Path     string // owner/name on GitHub, namespace/name with its subgroups on GitLab
+}
+
+// Metadata describes a repository as reported by its provider.
+type Metadata struct {
+	Stars    int        // Number of stars
+	Forks    int        // Number of forks
+	Language string     // Primary language, empty when unknown
+	Topics   []string   // Topics, or tags on older GitLab versions
+	PushedAt *time.Time // Last push on GitHub, last activity on GitLab
+	ETag     string     // Validator of the response, sent back by the next Fetch of the repository
+}
+
+// ErrNotModified is returned by Fetch when the repository did not change since the response with the given ETag.
+var ErrNotModified = errors.New("repository not modified")
+
+// ErrNotFound is returned by Fetch when the repository does not exist or is private.
+var ErrNotFound = errors.New("repository not found")
+
+// ErrRateLimited is matched by the RateLimitError returned when the rate limit of a provider is exhausted.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitError is returned by Fetch when the rate limit of a provider is exhausted.
+type RateLimitError struct {
+	Provider string    // Provider whose limit is exhausted
+	Reset    time.Time // When the provider accepts requests again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limit exceeded until %s", e.Provider, e.Reset.Format(time.RFC3339))
+}
+
+// Unwrap lets errors.Is match ErrRateLimited.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Config holds the settings of the providers.
+type Config struct {
+	GitHubHost  string        // Host of GitHub repository links, e.g. github.com
+	GitHubAPI   string        // Base URL of the GitHub REST API, e.g. https://api.github.com
+	GitHubToken string        // Optional token, which raises the rate limit
+	GitLabHost  string        // Host of GitLab repository links, e.g. gitlab.com
+	GitLabAPI   string        // Base URL of the GitLab REST API, e.g. https://gitlab.com/api/v4
+	GitLabToken string        // Optional personal access token
+	Timeout     time.Duration // Timeout of every request
+}
+
+// LoadConfig loads the settings of the providers from environment variables.
+//
+// Environment Variables:
+// - GITHUB_HOST: Host of GitHub repository links (default github.com).
+// - GITHUB_API_URL: Base URL of the GitHub REST API (default https://api.github.com).
+// - GITHUB_TOKEN: Optional token sent to GitHub.
+// - GITLAB_HOST: Host of GitLab repository links (default gitlab.com).
+// - GITLAB_API_URL: Base URL of the GitLab REST API (default https://gitlab.com/api/v4).
+// - GITLAB_TOKEN: Optional personal access token sent to GitLab.
+// - REPOSITORY_SYNC_TIMEOUT: Timeout of every request (default 10s).
+func LoadConfig() Config {
+	return Config{
+		GitHubHost:  utils.LoadEnvDefault("GITHUB_HOST", "github.com"),
+		GitHubAPI:   utils.LoadEnvDefault("GITHUB_API_URL", "https://api.github.com"),
+		GitHubToken: utils.LoadEnvDefault("GITHUB_TOKEN", ""),
+		GitLabHost:  utils.LoadEnvDefault("GITLAB_HOST", "gitlab.com"),
+		GitLabAPI:   utils.LoadEnvDefault("GITLAB_API_URL", "https://gitlab.com/api/v4"),
+		GitLabToken: utils.LoadEnvDefault("GITLAB_TOKEN", ""),
+		Timeout:     utils.LoadEnvDuration("REPOSITORY_SYNC_TIMEOUT", 10*time.Second),
+	}
+}
+
+// Parse returns the repository that link points to. It accepts web URLs, with or without their
+// scheme and including links to a file or branch of the repository, clone URLs and SCP-like SSH
+// addresses such as git@github.com:owner/name.git. It returns false for links to other hosts.
+func (c *Client) Parse(link string) (Repository, bool) {
+	link = strings.TrimSpace(link)
+	if user, address, ok := strings.Cut(link, "@"); ok && !strings.Contains(user, "://") && !strings.Contains(user, "/") {
+		// SCP-like address: user@host:path
+		host, path, ok := strings.Cut(address, ":")
+		if !ok {
+			return Repository{}, false
+		}
+		link = "ssh://" + user + "@" + host + "/" + path
+	} else if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return Repository{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	var segments []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		// GitLab separates the path of the repository from its pages with "-"
+		if segment == "-" {
+			break
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	switch host {
+	case strings.ToLower(c.config.GitHubHost):
+		if len(segments) < 2 {
+			return Repository{}, false
+		}
+		return Repository{Provider: GitHub, Path: segments[0] + "/" + strings.TrimSuffix(segments[1], ".git")}, true
+	case strings.ToLower(c.config.GitLabHost):
+		if len(segments) < 2 {
+			return Repository{}, false
+		}
+		segments[len(segments)-1] = strings.TrimSuffix(segments[len(segments)-1], ".git")
+		return Repository{Provider: GitLab, Path: strings.Join(segments, "/")}, true
+	}
+	return Repository{}, false
+}
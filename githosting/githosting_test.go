@@ -0,0 +1,180 @@
+package githosting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client whose providers are both served by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(Config{
+		GitHubHost: "github.com", GitHubAPI: server.URL, GitHubToken: "github-token",
+		GitLabHost: "gitlab.com", GitLabAPI: server.URL + "/api/v4", GitLabToken: "gitlab-token",
+		Timeout: 5 * time.Second,
+	})
+}
+
+func TestParse(t *testing.T) {
+	client := NewClient(Config{GitHubHost: "github.com", GitLabHost: "gitlab.com"})
+	tests := []struct {
+		link string
+		want Repository
+		ok   bool
+	}{
+		{"https://github.com/owner/name", Repository{GitHub, "owner/name"}, true},
+		{"https://www.github.com/owner/name/tree/main/docs", Repository{GitHub, "owner/name"}, true},
+		{"github.com/owner/name.git", Repository{GitHub, "owner/name"}, true},
+		{"git@github.com:owner/name.git", Repository{GitHub, "owner/name"}, true},
+		{"https://gitlab.com/group/subgroup/name/-/tree/main", Repository{GitLab, "group/subgroup/name"}, true},
+		{"ssh://git@gitlab.com/group/name.git", Repository{GitLab, "group/name"}, true},
+		{"https://github.com/owner", Repository{}, false},
+		{"https://bitbucket.org/owner/name", Repository{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := client.Parse(tt.link)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %v, %t, want %v, %t", tt.link, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFetchGitHub(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/name" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer github-token" {
+			t.Errorf("Authorization = %q, want the token", got)
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"stargazers_count":12,"forks_count":3,"language":"Go","topics":["api"],"pushed_at":"2024-05-01T10:00:00Z"}`))
+	})
+	repo := Repository{Provider: GitHub, Path: "owner/name"}
+
+	metadata, err := client.Fetch(context.Background(), repo, "")
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if metadata.Stars != 12 || metadata.Forks != 3 || metadata.Language != "Go" || len(metadata.Topics) != 1 || metadata.ETag != `"v1"` {
+		t.Errorf("Fetch() = %+v", metadata)
+	}
+	if metadata.PushedAt == nil || !metadata.PushedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("PushedAt = %v", metadata.PushedAt)
+	}
+
+	if _, err := client.Fetch(context.Background(), repo, `"v1"`); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Fetch() with the ETag returned %v, want ErrNotModified", err)
+	}
+	if _, err := client.Fetch(context.Background(), Repository{Provider: GitHub, Path: "owner/missing"}, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Fetch() of a missing repository returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestFetchGitLabLanguages(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "gitlab-token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want the token", got)
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fname":
+			_, _ = w.Write([]byte(`{"id":7,"star_count":5,"forks_count":1,"tag_list":["cli"]}`))
+		case "/api/v4/projects/7/languages":
+			_, _ = w.Write([]byte(`{"Shell":10.5,"Go":80.1,"Makefile":9.4}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	metadata, err := client.Fetch(context.Background(), Repository{Provider: GitLab, Path: "group/name"}, "")
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if metadata.Stars != 5 || metadata.Language != "Go" || len(metadata.Topics) != 1 || metadata.Topics[0] != "cli" {
+		t.Errorf("Fetch() = %+v", metadata)
+	}
+}
+
+func TestFetchRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/repos/owner/name" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	})
+	repo := Repository{Provider: GitHub, Path: "owner/name"}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Fetch(context.Background(), repo, "")
+		var limit *RateLimitError
+		if !errors.As(err, &limit) || !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("Fetch() returned %v, want a *RateLimitError", err)
+		}
+		if limit.Provider != GitHub || !limit.Reset.Equal(reset) {
+			t.Errorf("RateLimitError = %+v, want GitHub until %s", limit, reset)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("provider received %d requests, want 1: the exhausted limit must be respected", got)
+	}
+
+	// The limit of one provider does not stop the other
+	if _, err := client.Fetch(context.Background(), Repository{Provider: GitLab, Path: "group/name"}, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Fetch() from GitLab returned %v, want the response of GitLab", err)
+	}
+}
+
+func TestFetchRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client.now = func() time.Time { return now }
+
+	_, err := client.Fetch(context.Background(), Repository{Provider: GitLab, Path: "group/name"}, "")
+	var limit *RateLimitError
+	if !errors.As(err, &limit) || !limit.Reset.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("Fetch() returned %v, want a limit until 30 seconds later", err)
+	}
+
+	// Once the limit resets, the provider is called again
+	now = now.Add(time.Minute)
+	if _, err := client.Fetch(context.Background(), Repository{Provider: GitLab, Path: "group/name"}, ""); !errors.As(err, &limit) || !limit.Reset.Equal(now.Add(30*time.Second)) {
+		t.Errorf("Fetch() after the reset returned %v, want a new limit", err)
+	}
+}
+
+func TestFetchGitLabRateLimitHeaders(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := client.Fetch(context.Background(), Repository{Provider: GitLab, Path: "group/name"}, "")
+	var limit *RateLimitError
+	if !errors.As(err, &limit) || limit.Provider != GitLab || !limit.Reset.Equal(reset) {
+		t.Fatalf("Fetch() returned %v, want a GitLab limit until %s", err, reset)
+	}
+}
@@ -1,23 +1,39 @@
 package hooks
 
 import (
+	"context"
 	"net/smtp"
 	"log"
 
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/config/tracing"
 	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // SendEmail sends an email using SMTP with the specified parameters.
 // It constructs the email message from the provided recipient, subject, and body.
 // The sender's email and password are loaded from environment variables.
 //
+// The delivery is traced as a client span that is a child of the span found in ctx.
+//
 // Parameters:
+// - ctx: The context of the request that triggered the email.
 // - to: The recipient's email address.
 // - subject: The subject line of the email.
 // - body: The body content of the email.
 //
 // On success, it logs a message indicating the email was sent. On failure, it logs the error encountered.
-func SendEmail(to string, subject string, body string) {
+func SendEmail(ctx context.Context, to string, subject string, body string) {
+    _, span := tracing.Tracer().Start(ctx, "smtp.send",
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(attribute.String("server.address", "smtp.gmail.com")),
+    )
+    defer span.End()
+
     from := utils.LoadEnv("EMAIL_FROM")
     pass := utils.LoadEnv("EMAIL_PASSWORD")
 
@@ -32,9 +48,22 @@ func SendEmail(to string, subject string, body string) {
         smtp.PlainAuth("", from, pass, "smtp.gmail.com"),
         from, []string{to}, []byte(msg))
 
+    metrics.EmailsSent.WithLabelValues(metrics.Result(err)).Inc()
+
     if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
         log.Printf("smtp error: %s while sending to %s", err, to)
         return
     }
     log.Println("Successfully sent to " + to)
 }
+
+// SMTPMailer sends emails with SendEmail. It lets services depend on an interface
+// instead of the package function.
+type SMTPMailer struct{}
+
+// SendEmail sends an email with the package-level SendEmail function.
+func (SMTPMailer) SendEmail(ctx context.Context, to string, subject string, body string) {
+    SendEmail(ctx, to, subject, body)
+}
@@ -0,0 +1,197 @@
+// Package i18n selects the locale of the translated content of the portfolio.
+//
+// The untranslated fields of an entity hold the content in the default locale; the other
+// supported locales are stored as translations next to the entity. A read picks the locale
+// from the "lang" query parameter or the Accept-Language header, then takes every field from
+// the first locale of its fallback chain that has a value for it.
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Locales holds the supported locales and the fallback chain used when a translation is missing.
+type Locales struct {
+	supported []string // Supported locales, the default first
+	fallback  []string // Locales tried after the requested one, before the default
+	matcher   language.Matcher
+}
+
+// MissingTranslation reports the fields of an entity that have no translation in a locale.
+type MissingTranslation struct {
+	Entity string   `json:"entity"` // Type of the entity, e.g. about or project
+	ID     uint     `json:"id"`     // ID of the entity
+	Locale string   `json:"locale"` // Locale missing the translation
+	Fields []string `json:"fields"` // JSON names of the untranslated fields
+}
+
+// NewLocales returns the Locales supporting the given locales, the first being the default one.
+// fallback lists the locales tried, in order, when the requested locale has no value for a field.
+// It returns an error when a locale is not a valid BCP 47 tag or a fallback locale is not supported.
+func NewLocales(supported []string, fallback []string) (*Locales, error) {
+	if len(supported) == 0 {
+		return nil, fmt.Errorf("at least one locale must be supported")
+	}
+
+	l := &Locales{}
+	tags := make([]language.Tag, 0, len(supported))
+	for _, locale := range supported {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale %q: %w", locale, err)
+		}
+		tags = append(tags, tag)
+		l.supported = append(l.supported, tag.String())
+	}
+	for _, locale := range fallback {
+		tag, err := language.Parse(locale)
+		if err != nil || !l.IsSupported(tag.String()) {
+			return nil, fmt.Errorf("fallback locale %q is not supported", locale)
+		}
+		l.fallback = append(l.fallback, tag.String())
+	}
+	l.matcher = language.NewMatcher(tags)
+	return l, nil
+}
+
+// LoadLocales loads the supported locales from environment variables.
+// It exits the application when the configuration is invalid.
+//
+// Environment Variables:
+// - LOCALES: Comma-separated supported locales, the default first (default "en,id").
+// - LOCALE_FALLBACK: Comma-separated locales tried when a translation is missing, before the default (default none).
+func LoadLocales() *Locales {
+	locales, err := NewLocales(
+		splitList(utils.LoadEnvDefault("LOCALES", "en,id")),
+		splitList(utils.LoadEnvDefault("LOCALE_FALLBACK", "")),
+	)
+	if err != nil {
+		log.Fatalf("Invalid locale configuration: %v", err)
+	}
+	return locales
+}
+
+// splitList splits a comma-separated list, ignoring blank entries.
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// Default returns the locale of the untranslated fields.
+func (l *Locales) Default() string {
+	return l.supported[0]
+}
+
+// Supported returns every supported locale, the default first.
+func (l *Locales) Supported() []string {
+	return l.supported
+}
+
+// Translated returns the supported locales stored as translations, i.e. all but the default.
+func (l *Locales) Translated() []string {
+	return l.supported[1:]
+}
+
+// IsSupported reports whether locale is one of the supported locales.
+func (l *Locales) IsSupported(locale string) bool {
+	for _, supported := range l.supported {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the supported locale best matching lang, then the Accept-Language header value.
+// It returns the default locale when neither matches a supported locale.
+func (l *Locales) Match(lang string, acceptLanguage string) string {
+	var tags []language.Tag
+	if tag, err := language.Parse(lang); err == nil {
+		tags = append(tags, tag)
+	}
+	if accepted, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		tags = append(tags, accepted...)
+	}
+
+	_, index, confidence := l.matcher.Match(tags...)
+	if confidence == language.No {
+		return l.Default()
+	}
+	return l.supported[index]
+}
+
+// Negotiate returns the locale of the response to the request, as chosen by Match from the "lang"
+// query parameter and the Accept-Language header, and sets the Content-Language and Vary headers.
+func (l *Locales) Negotiate(c *gin.Context) string {
+	locale := l.Match(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return locale
+}
+
+// Chain returns the locales whose values are used for locale, in order: locale itself,
+// the fallback locales and the default locale.
+func (l *Locales) Chain(locale string) []string {
+	chain := []string{locale}
+	for _, next := range append(append([]string{}, l.fallback...), l.Default()) {
+		if !contains(chain, next) {
+			chain = append(chain, next)
+		}
+	}
+	return chain
+}
+
+// Pick returns the first non-empty value of values, keyed by locale, along the chain of locale.
+func (l *Locales) Pick(locale string, values map[string]string) string {
+	for _, next := range l.Chain(locale) {
+		if value := values[next]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Check validates the locales of the translations sent in the field of the request body:
+// each must be a translated locale and appear once. It returns one FieldError per invalid locale.
+func (l *Locales) Check(field string, locales []string) []apperrors.FieldError {
+	var fields []apperrors.FieldError
+	seen := map[string]bool{}
+	for i, locale := range locales {
+		name := fmt.Sprintf("%s[%d].locale", field, i)
+		switch {
+		case !contains(l.Translated(), locale):
+			fields = append(fields, apperrors.FieldError{
+				Field:   name,
+				Code:    "oneof",
+				Message: "must be one of: " + strings.Join(l.Translated(), ", "),
+			})
+		case seen[locale]:
+			fields = append(fields, apperrors.FieldError{Field: name, Code: "unique", Message: "must not be repeated"})
+		}
+		seen[locale] = true
+	}
+	return fields
+}
+
+// contains reports whether list holds value.
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestLocales returns English, Indonesian and Malay locales, falling back from Malay to Indonesian.
+func newTestLocales(t *testing.T, fallback ...string) *Locales {
+	t.Helper()
+	locales, err := NewLocales([]string{"en", "id", "ms"}, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return locales
+}
+
+func TestNewLocales(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported []string
+		fallback  []string
+		wantErr   bool
+	}{
+		{"default only", []string{"en"}, nil, false},
+		{"with fallback", []string{"en", "id", "ms"}, []string{"id"}, false},
+		{"canonical fallback", []string{"en", "pt-BR"}, []string{"pt-br"}, false},
+		{"no locale", nil, nil, true},
+		{"invalid locale", []string{"en", "not a locale"}, nil, true},
+		{"unsupported fallback", []string{"en", "id"}, []string{"ms"}, true},
+		{"invalid fallback", []string{"en", "id"}, []string{"??"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLocales(tt.supported, tt.fallback)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLocales(%q, %q) error = %v, want error: %t", tt.supported, tt.fallback, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		lang           string
+		acceptLanguage string
+		want           string
+	}{
+		{"", "", "en"},
+		{"id", "", "id"},
+		{"", "id-ID,id;q=0.9,en;q=0.8", "id"},
+		{"", "fr-FR,ms;q=0.5", "ms"},
+		// The query parameter wins over the header
+		{"ms", "id", "ms"},
+		{"en", "id", "en"},
+		// An unsupported or invalid parameter falls through to the header
+		{"fr", "id", "id"},
+		{"not a locale", "ms", "ms"},
+		{"", "fr, de;q=0.5", "en"},
+		{"", "invalid;;q=x", "en"},
+	}
+	locales := newTestLocales(t)
+	for _, tt := range tests {
+		if got := locales.Match(tt.lang, tt.acceptLanguage); got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, want %q", tt.lang, tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	locales := newTestLocales(t)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/about?lang=ms", nil)
+	c.Request.Header.Set("Accept-Language", "id")
+
+	if got := locales.Negotiate(c); got != "ms" {
+		t.Errorf("Negotiate() = %q, want ms", got)
+	}
+	if got := recorder.Header().Get("Content-Language"); got != "ms" {
+		t.Errorf("Content-Language = %q, want ms", got)
+	}
+	if got := recorder.Header().Values("Vary"); !reflect.DeepEqual(got, []string{"Accept-Language"}) {
+		t.Errorf("Vary = %q, want Accept-Language", got)
+	}
+}
+
+func TestChainAndPick(t *testing.T) {
+	locales := newTestLocales(t, "id")
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{"en", []string{"en", "id"}},
+		{"id", []string{"id", "en"}},
+		{"ms", []string{"ms", "id", "en"}},
+	}
+	for _, tt := range tests {
+		if got := locales.Chain(tt.locale); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chain(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	values := map[string]string{"en": "Hello", "id": "Halo"}
+	if got := locales.Pick("ms", values); got != "Halo" {
+		t.Errorf("Pick(ms) = %q, want the Indonesian fallback", got)
+	}
+	if got := locales.Pick("id", map[string]string{"en": "Hello", "id": ""}); got != "Hello" {
+		t.Errorf("Pick(id) = %q, want the default for an empty translation", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	locales := newTestLocales(t)
+
+	fields := locales.Check("translations", []string{"id", "en", "ms", "id"})
+	var got []string
+	for _, field := range fields {
+		got = append(got, field.Field+":"+field.Code)
+	}
+	want := []string{"translations[1].locale:oneof", "translations[3].locale:unique"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package images
+
+import "errors"
+
+// errTruncatedGIF is returned by gifFrames when a block runs past the end of the data.
+var errTruncatedGIF = errors.New("truncated GIF")
+
+// gifFrames counts the frames of a GIF image by walking its blocks, without decoding any pixels.
+func gifFrames(data []byte) (int, error) {
+	// Header and logical screen descriptor
+	if len(data) < 13 {
+		return 0, errTruncatedGIF
+	}
+	pos := 13 + colorTableSize(data[10])
+
+	frames := 0
+	for {
+		if pos >= len(data) {
+			return 0, errTruncatedGIF
+		}
+		switch data[pos] {
+		case 0x21: // Extension: introducer, label and data sub-blocks
+			pos += 2
+		case 0x2C: // Image descriptor, optional local color table, LZW code size and data sub-blocks
+			if pos+10 > len(data) {
+				return 0, errTruncatedGIF
+			}
+			pos += 10 + colorTableSize(data[pos+9]) + 1
+			frames++
+		case 0x3B: // Trailer
+			return frames, nil
+		default:
+			return 0, errors.New("invalid GIF block")
+		}
+
+		// Skip the data sub-blocks up to the block terminator
+		for {
+			if pos >= len(data) {
+				return 0, errTruncatedGIF
+			}
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				break
+			}
+		}
+	}
+}
+
+// colorTableSize returns the size in bytes of the color table described by the packed fields
+// of a logical screen or image descriptor.
+func colorTableSize(fields byte) int {
+	if fields&0x80 == 0 {
+		return 0
+	}
+	return 3 << (fields&0x07 + 1)
+}
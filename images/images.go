@@ -0,0 +1,187 @@
+// Package images validates uploaded images and removes their metadata.
+//
+// Uploads are identified by sniffing their content rather than trusting the declared content type.
+// Every image is decoded and encoded again, which drops EXIF, XMP and any other metadata such as
+// GPS coordinates; the EXIF orientation of JPEG images is applied to the pixels first, so that
+// the image keeps its orientation without the tag.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"github.com/chai2010/webp"
+	"github.com/gabriel-vasile/mimetype"
+	xwebp "golang.org/x/image/webp"
+)
+
+// Errors returned by Sanitize.
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")    // The content is not a supported image
+	ErrInvalidImage    = errors.New("invalid image")             // The content cannot be decoded
+	ErrTooManyPixels   = errors.New("image has too many pixels") // The image exceeds Limits.MaxPixels
+)
+
+// Supported content types of uploads.
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+)
+
+// SupportedTypes are the content types accepted by Sanitize.
+var SupportedTypes = []string{JPEG, PNG, GIF, WebP}
+
+// encodeQuality is the quality of re-encoded JPEG and WebP images.
+const encodeQuality = 90
+
+// Limits bounds the size of uploaded images.
+type Limits struct {
+	MaxBytes  int64 // Maximum size of the uploaded file
+	MaxPixels int   // Maximum width times height, times the frames of a GIF; bounds the memory used to decode the image
+}
+
+// LoadLimits loads the upload limits from environment variables.
+//
+// Environment Variables:
+// - IMAGE_MAX_BYTES: Maximum size of an uploaded image in bytes (default 10 MiB).
+// - IMAGE_MAX_PIXELS: Maximum number of pixels of an uploaded image (default 40 million).
+func LoadLimits() Limits {
+	return Limits{
+		MaxBytes:  int64(utils.LoadEnvInt("IMAGE_MAX_BYTES", 10<<20)),
+		MaxPixels: utils.LoadEnvInt("IMAGE_MAX_PIXELS", 40_000_000),
+	}
+}
+
+// Image is a sanitized image.
+type Image struct {
+	Data        []byte      // Encoded image without metadata
+	ContentType string      // Content type of Data
+	Extension   string      // File extension matching ContentType, with the dot
+	Width       int         // Width in pixels
+	Height      int         // Height in pixels
+	Pixels      image.Image // Decoded image, the first frame of an animated GIF; used to generate Variants
+}
+
+// Sanitize detects the type of data, checks its dimensions against maxPixels and encodes it
+// again without metadata.
+func Sanitize(data []byte, maxPixels int) (Image, error) {
+	contentType := mimetype.Detect(data).String()
+
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch contentType {
+	case JPEG:
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case PNG:
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	case GIF:
+		decode, decodeConfig = gif.Decode, gif.DecodeConfig
+	case WebP:
+		decode, decodeConfig = xwebp.Decode, xwebp.DecodeConfig
+	default:
+		return Image{}, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	// The dimensions are checked before decoding, so that a small file cannot expand into a huge bitmap
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return Image{}, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if config.Width*config.Height > maxPixels {
+		return Image{}, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, config.Width, config.Height)
+	}
+
+	if contentType == GIF {
+		return sanitizeGIF(data, config, maxPixels)
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	var buf bytes.Buffer
+	result := Image{}
+	switch contentType {
+	case JPEG:
+		img = orient(img, orientation(data))
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: encodeQuality})
+		result.ContentType, result.Extension = JPEG, ".jpg"
+	case WebP:
+		nrgba := image.NewNRGBA(img.Bounds())
+		draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		img = nrgba
+		err = encodeWebP(&buf, nrgba, encodeQuality)
+		result.ContentType, result.Extension = WebP, ".webp"
+	default:
+		err = png.Encode(&buf, img)
+		result.ContentType, result.Extension = PNG, ".png"
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	bounds := img.Bounds()
+	result.Data, result.Width, result.Height, result.Pixels = buf.Bytes(), bounds.Dx(), bounds.Dy(), img
+	return result, nil
+}
+
+// sanitizeGIF encodes the frames of a GIF again, keeping its animation but dropping comments
+// and application extensions other than the loop count. Every frame is decoded into a bitmap of
+// up to the canvas size, so the frames times the canvas are checked against maxPixels first.
+func sanitizeGIF(data []byte, config image.Config, maxPixels int) (Image, error) {
+	frames, err := gifFrames(data)
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if frames*config.Width*config.Height > maxPixels {
+		return Image{}, fmt.Errorf("%w: %d frames of %dx%d", ErrTooManyPixels, frames, config.Width, config.Height)
+	}
+
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return Image{}, err
+	}
+
+	// Frames may cover only part of the canvas, so the first one is drawn onto a canvas of the full size
+	canvas := image.NewNRGBA(image.Rect(0, 0, animation.Config.Width, animation.Config.Height))
+	if len(animation.Image) > 0 {
+		first := animation.Image[0]
+		draw.Draw(canvas, first.Bounds(), first, first.Bounds().Min, draw.Src)
+	}
+
+	return Image{
+		Data:        buf.Bytes(),
+		ContentType: GIF,
+		Extension:   ".gif",
+		Width:       animation.Config.Width,
+		Height:      animation.Config.Height,
+		Pixels:      canvas,
+	}, nil
+}
+
+// encodeWebP writes img to w as a lossy WebP image with quality.
+func encodeWebP(w io.Writer, img *image.NRGBA, quality float32) error {
+	// libwebp expects non-premultiplied pixels, which the encoder only passes through unchanged for
+	// *image.RGBA; any other type is converted into premultiplied pixels, darkening translucent edges
+	return webp.Encode(w, &image.RGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}, &webp.Options{Quality: quality})
+}
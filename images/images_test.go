@@ -0,0 +1,63 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+)
+
+// encodeGIF returns an animated GIF of frames frames of width by height pixels.
+func encodeGIF(t *testing.T, frames int, width int, height int) []byte {
+	t.Helper()
+	animation := &gif.GIF{LoopCount: 0}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+		frame.Pix[0] = uint8(i)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSanitizeGIFFrameLimit(t *testing.T) {
+	data := encodeGIF(t, 3, 10, 10)
+
+	img, err := Sanitize(data, 300)
+	if err != nil {
+		t.Fatalf("Sanitize() with room for 3 frames returned error: %v", err)
+	}
+	if img.ContentType != GIF || img.Width != 10 || img.Height != 10 {
+		t.Errorf("Sanitize() = %s %dx%d, want a 10x10 GIF", img.ContentType, img.Width, img.Height)
+	}
+
+	// A single frame fits, but not the whole animation
+	if _, err := Sanitize(data, 299); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Sanitize() with room for less than 3 frames = %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestGIFFrames(t *testing.T) {
+	for _, frames := range []int{1, 2, 7} {
+		got, err := gifFrames(encodeGIF(t, frames, 4, 3))
+		if err != nil || got != frames {
+			t.Errorf("gifFrames() of %d frames = %d, %v", frames, got, err)
+		}
+	}
+
+	data := encodeGIF(t, 2, 4, 3)
+	for _, n := range []int{0, 12, 13, len(data) / 2, len(data) - 1} {
+		if _, err := gifFrames(data[:n]); err == nil {
+			t.Errorf("gifFrames() of the first %d bytes returned no error", n)
+		}
+	}
+	if _, err := Sanitize(data[:len(data)-1], 1000); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Sanitize() of a truncated GIF = %v, want ErrInvalidImage", err)
+	}
+}
@@ -0,0 +1,151 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+)
+
+// Size is a named maximum width of a variant.
+type Size struct {
+	Name  string // Name of the variant, e.g. thumbnail
+	Width int    // Maximum width in pixels
+}
+
+// Sizes are the variants generated for every image, from the smallest to the largest.
+var Sizes = []Size{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 768},
+	{Name: "large", Width: 1600},
+}
+
+// variantQuality is the quality of JPEG and WebP variants, lower than encodeQuality since variants are
+// meant to be downloaded by browsers rather than kept as the original.
+const variantQuality = 82
+
+// placeholderWidth is the width of the copy of the image that placeholders are computed from.
+const placeholderWidth = 32
+
+// Variant is a resized copy of an image.
+type Variant struct {
+	Name        string // Name of the Size
+	Data        []byte // Encoded image
+	ContentType string // Content type of Data
+	Extension   string // File extension matching ContentType, with the dot
+	Width       int    // Width in pixels
+	Height      int    // Height in pixels
+}
+
+// Variants resizes img to every Size, keeping its aspect ratio. Images are never enlarged, so a
+// variant wider than img has the dimensions of img. Opaque variants are encoded as JPEG and the
+// others as lossy WebP, which keeps their transparency at a fraction of the size of PNG.
+// Variants of an animated GIF show its first frame.
+func Variants(img Image) ([]Variant, error) {
+	variants := make([]Variant, 0, len(Sizes))
+	for _, size := range Sizes {
+		resized := resize(img.Pixels, size.Width)
+
+		var buf bytes.Buffer
+		variant := Variant{Name: size.Name, Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+		var err error
+		if resized.Opaque() {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: variantQuality})
+			variant.ContentType, variant.Extension = JPEG, ".jpg"
+		} else {
+			err = encodeWebP(&buf, resized, variantQuality)
+			variant.ContentType, variant.Extension = WebP, ".webp"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s variant: %w", size.Name, err)
+		}
+		variant.Data = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// Placeholder is shown by clients while an image loads.
+type Placeholder struct {
+	BlurHash string // BlurHash of the image, see https://blurha.sh
+	Color    string // Dominant color of the image as #rrggbb
+}
+
+// NewPlaceholder computes the placeholder of img from a small copy of it.
+func NewPlaceholder(img Image) (Placeholder, error) {
+	small := resize(img.Pixels, placeholderWidth)
+
+	// More components are used along the longer side, so that the blur keeps the layout of the image
+	x, y := 4, 3
+	if small.Bounds().Dy() > small.Bounds().Dx() {
+		x, y = 3, 4
+	}
+	hash, err := blurhash.Encode(x, y, small)
+	if err != nil {
+		return Placeholder{}, fmt.Errorf("encoding blurhash: %w", err)
+	}
+	return Placeholder{BlurHash: hash, Color: dominantColor(small)}, nil
+}
+
+// resize scales img down to width, keeping its aspect ratio; narrower images keep their size.
+func resize(img image.Image, width int) *image.NRGBA {
+	bounds := img.Bounds()
+	height := bounds.Dy()
+	if bounds.Dx() > width {
+		height = max(1, (bounds.Dy()*width+bounds.Dx()/2)/bounds.Dx())
+	} else {
+		width = bounds.Dx()
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// dominantColor returns the most common color of img as #rrggbb. Colors are grouped into
+// buckets of 4 bits per channel and the pixels of the largest bucket are averaged; pixels that
+// are mostly transparent are ignored.
+func dominantColor(img *image.NRGBA) string {
+	type bucket struct{ count, r, g, b int }
+	buckets := map[int]*bucket{}
+	var best *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				continue
+			}
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			b := buckets[key]
+			if b == nil {
+				b = &bucket{}
+				buckets[key] = b
+			}
+			b.count++
+			b.r, b.g, b.b = b.r+int(c.R), b.g+int(c.G), b.b+int(c.B)
+			if best == nil || b.count > best.count {
+				best = b
+			}
+		}
+	}
+
+	if best == nil {
+		return hexColor(color.NRGBA{})
+	}
+	return hexColor(color.NRGBA{
+		R: uint8(best.r / best.count),
+		G: uint8(best.g / best.count),
+		B: uint8(best.b / best.count),
+	})
+}
+
+// hexColor formats c as #rrggbb, ignoring its alpha.
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,245 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+
+	"gorm.io/gorm"
+)
+
+// Retention rule names reported by the retention job.
+const (
+	RuleSpam     = "spam"     // Hard-delete messages flagged as spam
+	RuleArchived = "archived" // Anonymize archived messages
+	RuleDeleted  = "deleted"  // Purge soft-deleted messages
+)
+
+// RetentionPolicy holds the retention rules for contact messages.
+// A rule with a non-positive number of days is disabled.
+type RetentionPolicy struct {
+	SpamDays     int  `json:"spamDays"`     // Hard-delete spam older than this many days
+	ArchivedDays int  `json:"archivedDays"` // Anonymize messages archived more than this many days ago
+	DeletedDays  int  `json:"deletedDays"`  // Purge rows soft-deleted more than this many days ago
+	DryRun       bool `json:"dryRun"`       // Only count matching rows without changing them
+}
+
+// LoadRetentionPolicy loads the retention policy from environment variables.
+//
+// Environment Variables:
+// - RETENTION_SPAM_DAYS: Days before spam is hard-deleted (default 30).
+// - RETENTION_ARCHIVED_DAYS: Days after archiving before messages are anonymized (default 365).
+// - RETENTION_DELETED_DAYS: Days before soft-deleted messages are purged (default 90).
+// - RETENTION_DRY_RUN: When true, the job only reports what it would do (default false).
+func LoadRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		SpamDays:     utils.LoadEnvInt("RETENTION_SPAM_DAYS", 30),
+		ArchivedDays: utils.LoadEnvInt("RETENTION_ARCHIVED_DAYS", 365),
+		DeletedDays:  utils.LoadEnvInt("RETENTION_DELETED_DAYS", 90),
+		DryRun:       utils.LoadEnvBool("RETENTION_DRY_RUN", false),
+	}
+}
+
+// RetentionRuleResult describes what a single retention rule did during a run.
+type RetentionRuleResult struct {
+	Rule   string    `json:"rule"`   // Name of the rule
+	Action string    `json:"action"` // Action applied to matching rows (delete, anonymize or purge)
+	Cutoff time.Time `json:"cutoff"` // Rows older than this time matched the rule
+	Rows   int64     `json:"rows"`   // Number of rows touched, or that would be touched in a dry run
+}
+
+// RetentionReport is the outcome of one run of the retention job.
+type RetentionReport struct {
+	StartedAt  time.Time             `json:"startedAt"`
+	FinishedAt time.Time             `json:"finishedAt"`
+	DryRun     bool                  `json:"dryRun"`
+	Results    []RetentionRuleResult `json:"results"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// RetentionSummary aggregates the reports of all runs since the service started.
+type RetentionSummary struct {
+	Policy     RetentionPolicy  `json:"policy"`
+	Runs       int64            `json:"runs"`
+	Failures   int64            `json:"failures"`
+	RowsByRule map[string]int64 `json:"rowsByRule"` // Rows touched per rule, dry runs excluded
+	LastReport *RetentionReport `json:"lastReport"`
+}
+
+// retentionStats holds the in-memory metrics of the scheduled retention job.
+var retentionStats = struct {
+	sync.Mutex
+	summary RetentionSummary
+}{summary: RetentionSummary{RowsByRule: map[string]int64{}}}
+
+// RetentionStats returns a snapshot of the metrics collected by the scheduled retention job.
+func RetentionStats() RetentionSummary {
+	retentionStats.Lock()
+	defer retentionStats.Unlock()
+
+	summary := retentionStats.summary
+	summary.RowsByRule = make(map[string]int64, len(retentionStats.summary.RowsByRule))
+	for rule, rows := range retentionStats.summary.RowsByRule {
+		summary.RowsByRule[rule] = rows
+	}
+	return summary
+}
+
+// RetentionJob applies the retention policy to contact messages.
+type RetentionJob struct {
+	db     *gorm.DB
+	cache  cache.Cache
+	policy RetentionPolicy
+}
+
+// NewRetentionJob creates a retention job that works on db and invalidates cached contacts in c.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewRetentionJob(db *gorm.DB, c cache.Cache, policy RetentionPolicy) *RetentionJob {
+	if db == nil || c == nil {
+		panic("jobs: nil dependency for the retention job")
+	}
+	return &RetentionJob{db: db, cache: c, policy: policy}
+}
+
+// Run executes the retention policy once and records the result in the job metrics.
+// It is meant to be registered on a Scheduler.
+func (j *RetentionJob) Run(ctx context.Context) error {
+	report, err := j.Apply(ctx, j.policy.DryRun)
+
+	retentionStats.Lock()
+	defer retentionStats.Unlock()
+
+	retentionStats.summary.Policy = j.policy
+	retentionStats.summary.Runs++
+	if err != nil {
+		retentionStats.summary.Failures++
+	}
+	if !report.DryRun {
+		for _, result := range report.Results {
+			retentionStats.summary.RowsByRule[result.Rule] += result.Rows
+			metrics.RetentionRows.WithLabelValues(result.Rule).Add(float64(result.Rows))
+		}
+	}
+	retentionStats.summary.LastReport = &report
+
+	return err
+}
+
+// retentionRule is one rule of the policy: the rows it matches and the action applied to them.
+type retentionRule struct {
+	name   string
+	action string
+	days   int
+	match  func(tx *gorm.DB, cutoff time.Time) *gorm.DB
+	apply  func(tx *gorm.DB) *gorm.DB
+}
+
+// rules returns the rules of the policy in the order they are applied.
+// Every rule works on unscoped queries so that soft-deleted rows are included.
+func (j *RetentionJob) rules() []retentionRule {
+	return []retentionRule{
+		{
+			name:   RuleSpam,
+			action: "delete",
+			days:   j.policy.SpamDays,
+			match: func(tx *gorm.DB, cutoff time.Time) *gorm.DB {
+				return tx.Where("status = ? AND created_at < ?", contactmodels.StatusSpam, cutoff)
+			},
+			apply: func(tx *gorm.DB) *gorm.DB {
+				return tx.Delete(&contactmodels.Contact{})
+			},
+		},
+		{
+			name:   RuleArchived,
+			action: "anonymize",
+			days:   j.policy.ArchivedDays,
+			match: func(tx *gorm.DB, cutoff time.Time) *gorm.DB {
+				// Messages archived before archived_at existed were last updated when they were archived
+				return tx.Where("status = ? AND COALESCE(archived_at, updated_at) < ? AND anonymized_at IS NULL", contactmodels.StatusArchived, cutoff)
+			},
+			apply: func(tx *gorm.DB) *gorm.DB {
+				return tx.Updates(map[string]interface{}{
+					"name":          "",
+					"email":         "",
+					"subject":       "",
+					"message":       "",
+					"anonymized_at": time.Now(),
+				})
+			},
+		},
+		{
+			name:   RuleDeleted,
+			action: "purge",
+			days:   j.policy.DeletedDays,
+			match: func(tx *gorm.DB, cutoff time.Time) *gorm.DB {
+				return tx.Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+			},
+			apply: func(tx *gorm.DB) *gorm.DB {
+				return tx.Delete(&contactmodels.Contact{})
+			},
+		},
+	}
+}
+
+// Apply runs every enabled rule of the policy and returns a report of the rows each rule touched.
+// When dryRun is true, matching rows are only counted and the database is left unchanged.
+func (j *RetentionJob) Apply(ctx context.Context, dryRun bool) (RetentionReport, error) {
+	now := time.Now()
+	report := RetentionReport{StartedAt: now, DryRun: dryRun, Results: []RetentionRuleResult{}}
+
+	var touched int64
+	for _, rule := range j.rules() {
+		if rule.days <= 0 {
+			continue
+		}
+
+		cutoff := now.AddDate(0, 0, -rule.days)
+		query := rule.match(j.db.WithContext(ctx).Unscoped().Model(&contactmodels.Contact{}), cutoff)
+
+		var rows int64
+		var err error
+		if dryRun {
+			err = query.Count(&rows).Error
+		} else {
+			result := rule.apply(query)
+			rows, err = result.RowsAffected, result.Error
+		}
+		if err != nil {
+			log.Printf("Retention rule %s failed: %v", rule.name, err)
+			report.FinishedAt = time.Now()
+			report.Error = err.Error()
+			return report, err
+		}
+
+		log.Printf("Retention rule %s (%s, dry run %t) matched %d rows older than %s",
+			rule.name, rule.action, dryRun, rows, cutoff.Format(time.RFC3339))
+
+		report.Results = append(report.Results, RetentionRuleResult{
+			Rule:   rule.name,
+			Action: rule.action,
+			Cutoff: cutoff,
+			Rows:   rows,
+		})
+		touched += rows
+	}
+
+	if !dryRun && touched > 0 {
+		j.invalidateCache(ctx)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// invalidateCache removes every cached contact response so that reads reflect the retention changes.
+func (j *RetentionJob) invalidateCache(ctx context.Context) {
+	if err := j.cache.DeletePrefix(ctx, "contact:"); err != nil {
+		log.Printf("Error deleting cached contacts from Redis: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeCache records the deleted key prefixes.
+type fakeCache struct {
+	cache.Cache
+	prefixes []string
+}
+
+func (c *fakeCache) DeletePrefix(ctx context.Context, prefix string) error {
+	c.prefixes = append(c.prefixes, prefix)
+	return nil
+}
+
+// newTestDB returns an in-memory database holding contacts.
+func newTestDB(t *testing.T, contacts []contactmodels.Contact) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&contactmodels.Contact{}); err != nil {
+		t.Fatal(err)
+	}
+	// GORM keeps the timestamps set by the fixture
+	if err := db.Create(&contacts).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// daysAgo returns the time the given number of days before now.
+func daysAgo(days int) time.Time {
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// contact returns a message with the given name, status and creation and update times.
+func contact(name string, status string, created time.Time, updated time.Time) contactmodels.Contact {
+	c := contactmodels.Contact{Name: name, Email: name + "@example.com", Message: "Hi", Status: status}
+	c.CreatedAt, c.UpdatedAt = created, updated
+	return c
+}
+
+func retentionFixture() []contactmodels.Contact {
+	oldSpam := contact("old-spam", contactmodels.StatusSpam, daysAgo(40), daysAgo(40))
+	newSpam := contact("new-spam", contactmodels.StatusSpam, daysAgo(10), daysAgo(10))
+	newMessage := contact("old-new", contactmodels.StatusNew, daysAgo(400), daysAgo(400))
+
+	// Created long ago but archived recently: kept until a year after archiving
+	recentlyArchived := contact("recently-archived", contactmodels.StatusArchived, daysAgo(400), daysAgo(10))
+	archivedAt := daysAgo(10)
+	recentlyArchived.ArchivedAt = &archivedAt
+	oldArchived := contact("old-archived", contactmodels.StatusArchived, daysAgo(500), daysAgo(5))
+	oldArchivedAt := daysAgo(400)
+	oldArchived.ArchivedAt = &oldArchivedAt
+	// Archived before archived_at existed: the last update is the archiving time
+	legacyArchived := contact("legacy-archived", contactmodels.StatusArchived, daysAgo(500), daysAgo(380))
+
+	oldDeleted := contact("old-deleted", contactmodels.StatusNew, daysAgo(200), daysAgo(200))
+	oldDeleted.DeletedAt = gorm.DeletedAt{Time: daysAgo(100), Valid: true}
+	newDeleted := contact("new-deleted", contactmodels.StatusNew, daysAgo(200), daysAgo(200))
+	newDeleted.DeletedAt = gorm.DeletedAt{Time: daysAgo(10), Valid: true}
+
+	return []contactmodels.Contact{oldSpam, newSpam, newMessage, recentlyArchived, oldArchived, legacyArchived, oldDeleted, newDeleted}
+}
+
+var testPolicy = RetentionPolicy{SpamDays: 30, ArchivedDays: 365, DeletedDays: 90}
+
+func TestRetentionApply(t *testing.T) {
+	db := newTestDB(t, retentionFixture())
+	c := &fakeCache{}
+
+	report, err := NewRetentionJob(db, c, testPolicy).Apply(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+	rows := map[string]int64{}
+	for _, result := range report.Results {
+		rows[result.Rule] = result.Rows
+	}
+	if rows[RuleSpam] != 1 || rows[RuleArchived] != 2 || rows[RuleDeleted] != 1 {
+		t.Errorf("rows by rule = %v, want 1 spam, 2 archived and 1 deleted", rows)
+	}
+
+	var remaining []contactmodels.Contact
+	if err := db.Unscoped().Order("id").Find(&remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	kept := map[string]contactmodels.Contact{}
+	anonymized := 0
+	for _, contact := range remaining {
+		if contact.AnonymizedAt != nil {
+			anonymized++
+			if contact.Name != "" || contact.Email != "" || contact.Message != "" {
+				t.Errorf("anonymized contact %d keeps its content: %+v", contact.ID, contact)
+			}
+			continue
+		}
+		kept[contact.Name] = contact
+	}
+	for _, name := range []string{"new-spam", "old-new", "recently-archived", "new-deleted"} {
+		if _, ok := kept[name]; !ok {
+			t.Errorf("contact %s was removed or anonymized, want it kept", name)
+		}
+	}
+	for _, name := range []string{"old-spam", "old-archived", "legacy-archived", "old-deleted"} {
+		if _, ok := kept[name]; ok {
+			t.Errorf("contact %s was kept, want it removed or anonymized", name)
+		}
+	}
+	if len(remaining) != 6 || anonymized != 2 {
+		t.Errorf("%d rows remain with %d anonymized, want 6 with 2 anonymized", len(remaining), anonymized)
+	}
+	if len(c.prefixes) != 1 || c.prefixes[0] != "contact:" {
+		t.Errorf("deleted cache prefixes = %v, want the contacts", c.prefixes)
+	}
+
+	// Anonymized messages are not counted again
+	report, err = NewRetentionJob(db, c, testPolicy).Apply(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range report.Results {
+		if result.Rows != 0 {
+			t.Errorf("second run of rule %s matched %d rows, want 0", result.Rule, result.Rows)
+		}
+	}
+}
+
+func TestRetentionDryRun(t *testing.T) {
+	db := newTestDB(t, retentionFixture())
+	c := &fakeCache{}
+
+	report, err := NewRetentionJob(db, c, testPolicy).Apply(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Apply() returned error: %v", err)
+	}
+	if !report.DryRun || len(report.Results) != 3 {
+		t.Fatalf("report = %+v, want a dry run of three rules", report)
+	}
+	var total int64
+	for _, result := range report.Results {
+		total += result.Rows
+	}
+	if total != 4 {
+		t.Errorf("dry run matched %d rows, want 4", total)
+	}
+
+	var count, anonymized int64
+	db.Unscoped().Model(&contactmodels.Contact{}).Count(&count)
+	db.Unscoped().Model(&contactmodels.Contact{}).Where("anonymized_at IS NOT NULL").Count(&anonymized)
+	if count != 8 || anonymized != 0 || len(c.prefixes) != 0 {
+		t.Errorf("dry run left %d rows with %d anonymized and deleted %v from the cache, want the data untouched", count, anonymized, c.prefixes)
+	}
+}
+
+func TestRetentionDisabledRules(t *testing.T) {
+	db := newTestDB(t, retentionFixture())
+
+	report, err := NewRetentionJob(db, &fakeCache{}, RetentionPolicy{SpamDays: 30}).Apply(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Rule != RuleSpam {
+		t.Errorf("results = %+v, want only the spam rule", report.Results)
+	}
+}
@@ -0,0 +1,89 @@
+// Package jobs contains background jobs that run periodically alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+)
+
+// JobFunc is the function executed by the Scheduler on every run of a job.
+type JobFunc func(ctx context.Context) error
+
+// scheduledJob is a job registered on the Scheduler together with its interval.
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs at a fixed interval until its context is cancelled.
+// Each job runs in its own goroutine, and runs of the same job never overlap.
+type Scheduler struct {
+	jobs []scheduledJob
+	wg   sync.WaitGroup
+}
+
+// NewScheduler returns an empty Scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers a job that runs once when the scheduler starts and then every interval.
+// Jobs with a non-positive interval are ignored, which allows disabling a job through configuration.
+//
+// Example:
+//
+//	scheduler.Every("retention", 24*time.Hour, retentionJob.Run)
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	if interval <= 0 {
+		log.Printf("Job %s disabled (interval %s)", name, interval)
+		return
+	}
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+// Start launches all registered jobs in the background.
+// The jobs stop when ctx is cancelled; use Wait to block until they have finished.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job scheduledJob) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until every job started by Start has returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop runs a single job immediately and then on every tick of its interval.
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	log.Printf("Job %s scheduled every %s", job.name, job.interval)
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		err := job.run(ctx)
+		metrics.JobRuns.WithLabelValues(job.name, metrics.Result(err)).Inc()
+		if err != nil {
+			log.Printf("Job %s failed after %s: %v", job.name, time.Since(start), err)
+		} else {
+			log.Printf("Job %s finished in %s", job.name, time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package linkcheck checks that URLs still lead somewhere, to find links that broke silently.
+//
+// Every URL is requested with HEAD, and with GET when the server does not support HEAD.
+// Requests have a timeout and only a bounded number of them run at the same time.
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+)
+
+// userAgent identifies the requests of the Checker to the servers it checks.
+const userAgent = "go-ms-portfolio-linkcheck/1.0"
+
+// maxBodyBytes bounds the part of a GET response that is read, so that the connection can be reused.
+const maxBodyBytes = 64 << 10
+
+// Config holds the settings of a Checker.
+type Config struct {
+	Timeout     time.Duration // Timeout of every check, redirects included
+	Concurrency int           // Maximum number of checks running at the same time
+}
+
+// LoadConfig loads the settings of the checker from environment variables.
+//
+// Environment Variables:
+// - LINK_CHECK_TIMEOUT: Timeout of every check (default 10s).
+// - LINK_CHECK_CONCURRENCY: Maximum number of checks running at the same time (default 8).
+func LoadConfig() Config {
+	return Config{
+		Timeout:     utils.LoadEnvDuration("LINK_CHECK_TIMEOUT", 10*time.Second),
+		Concurrency: utils.LoadEnvInt("LINK_CHECK_CONCURRENCY", 8),
+	}
+}
+
+// Result is the outcome of the check of one URL.
+type Result struct {
+	URL    string // Checked URL
+	Status int    // Status of the final response, after redirects; 0 when no response was received
+	Error  string // Why no response was received
+	Broken bool   // Whether the link is considered broken
+}
+
+// Checker checks URLs. It is safe for concurrent use.
+type Checker struct {
+	config Config
+	client *http.Client
+}
+
+// NewChecker returns a Checker for config. A concurrency below 1 checks one URL at a time.
+func NewChecker(config Config) *Checker {
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	return &Checker{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Check checks every URL of urls and returns their results in the same order.
+func (c *Checker) Check(ctx context.Context, urls []string) []Result {
+	results := make([]Result, len(urls))
+	slots := make(chan struct{}, c.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				results[i] = c.check(ctx, url)
+			case <-ctx.Done():
+				results[i] = Result{URL: url, Error: ctx.Err().Error()}
+			}
+		}(i, url)
+	}
+	wg.Wait()
+	return results
+}
+
+// check requests url with HEAD, falling back to GET when the server rejects HEAD.
+func (c *Checker) check(ctx context.Context, url string) Result {
+	status, err := c.request(ctx, http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+		status, err = c.request(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		// A cancelled run says nothing about the link
+		return Result{URL: url, Error: err.Error(), Broken: ctx.Err() == nil}
+	}
+	return Result{URL: url, Status: status, Broken: broken(status)}
+}
+
+// request sends a request with method for url and returns the status of the final response.
+func (c *Checker) request(ctx context.Context, method string, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyBytes))
+	return resp.StatusCode, nil
+}
+
+// broken reports whether a response with status means that the link is broken. Responses refusing
+// the checker access (401, 403) or asking it to slow down (429) show that the target exists.
+func broken(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return false
+	}
+	return status >= 400
+}
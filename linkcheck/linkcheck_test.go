@@ -0,0 +1,167 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), userAgent)
+		}
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			// The server only supports GET
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/no-head-gone":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotImplemented)
+				return
+			}
+			w.WriteHeader(http.StatusGone)
+		case "/moved":
+			http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+		case "/private":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: 5 * time.Second, Concurrency: 2})
+	tests := []struct {
+		path   string
+		status int
+		broken bool
+	}{
+		{"/ok", http.StatusOK, false},
+		{"/no-head", http.StatusOK, false},
+		{"/no-head-gone", http.StatusGone, true},
+		{"/moved", http.StatusNotFound, true},
+		{"/private", http.StatusUnauthorized, false},
+		{"/busy", http.StatusTooManyRequests, false},
+		{"/error", http.StatusInternalServerError, true},
+	}
+	urls := make([]string, len(tests))
+	for i, tt := range tests {
+		urls[i] = server.URL + tt.path
+	}
+
+	results := checker.Check(context.Background(), urls)
+	if len(results) != len(tests) {
+		t.Fatalf("Check() returned %d results, want %d", len(results), len(tests))
+	}
+	for i, tt := range tests {
+		got := results[i]
+		if got.URL != urls[i] || got.Status != tt.status || got.Broken != tt.broken || got.Error != "" {
+			t.Errorf("result of %s = %+v, want status %d and broken %t", tt.path, got, tt.status, tt.broken)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	gets := map[string]bool{}
+	for _, request := range methods {
+		if path, ok := strings.CutPrefix(request, "GET "); ok {
+			gets[path] = true
+		}
+	}
+	if !gets["/no-head"] || !gets["/no-head-gone"] || gets["/ok"] {
+		t.Errorf("GET was sent for %v, want it only as the fallback of servers rejecting HEAD", gets)
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	results := NewChecker(Config{Timeout: time.Second, Concurrency: 1}).Check(context.Background(), []string{url})
+	if !results[0].Broken || results[0].Status != 0 || results[0].Error == "" {
+		t.Errorf("result of a closed server = %+v, want a broken link with an error", results[0])
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	results := NewChecker(Config{Timeout: 50 * time.Millisecond, Concurrency: 1}).Check(context.Background(), []string{server.URL})
+	if !results[0].Broken || results[0].Error == "" {
+		t.Errorf("result of a slow server = %+v, want a broken link with an error", results[0])
+	}
+}
+
+func TestCheckCancelled(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := NewChecker(Config{Timeout: time.Second, Concurrency: 1}).Check(ctx, []string{server.URL, server.URL})
+	for _, result := range results {
+		// A cancelled run says nothing about the link
+		if result.Broken || result.Error == "" {
+			t.Errorf("result of a cancelled check = %+v, want an error without marking the link broken", result)
+		}
+	}
+}
+
+func TestCheckConcurrency(t *testing.T) {
+	const concurrency = 3
+	var running, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := peak.Load()
+			if now <= previous || peak.CompareAndSwap(previous, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 12)
+	for i := range urls {
+		urls[i] = server.URL
+	}
+	results := NewChecker(Config{Timeout: 5 * time.Second, Concurrency: concurrency}).Check(context.Background(), urls)
+	for _, result := range results {
+		if result.Broken {
+			t.Fatalf("result = %+v, want a working link", result)
+		}
+	}
+	if got := peak.Load(); got > concurrency || got < 2 {
+		t.Errorf("peak of concurrent checks = %d, want between 2 and %d", got, concurrency)
+	}
+}
@@ -4,17 +4,47 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
 	"github.com/EkoAgustina/go-ms-portfolio/config/database"
+	"github.com/EkoAgustina/go-ms-portfolio/config/logger"
 	"github.com/EkoAgustina/go-ms-portfolio/config/redis"
+	"github.com/EkoAgustina/go-ms-portfolio/config/tracing"
+	"github.com/EkoAgustina/go-ms-portfolio/controllers/aboutControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/controllers/contactControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/controllers/projectControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/githosting"
+	"github.com/EkoAgustina/go-ms-portfolio/hooks"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
+	"github.com/EkoAgustina/go-ms-portfolio/images"
+	"github.com/EkoAgustina/go-ms-portfolio/jobs"
+	"github.com/EkoAgustina/go-ms-portfolio/linkcheck"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/aboutRepositories"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/contactRepositories"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/projectRepositories"
 	"github.com/EkoAgustina/go-ms-portfolio/routes"
 	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/services/aboutServices"
+	"github.com/EkoAgustina/go-ms-portfolio/services/contactServices"
+	"github.com/EkoAgustina/go-ms-portfolio/services/projectServices"
+	"github.com/EkoAgustina/go-ms-portfolio/storage"
 	"github.com/EkoAgustina/go-ms-portfolio/utils"
 	"github.com/gin-gonic/gin"
 )
 var ctx = context.Background()
 func main () {
-	database.Connect()
+	logConfig := logger.LoadConfig()
+	logger.Setup(logConfig)
+
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(ctx)
+
+	db := database.Connect()
 	// Set up Redis
 	rdb, err := redis.SetupRedis(ctx)
 	if err != nil {
@@ -29,13 +59,72 @@ func main () {
 
 	log.Println("Successfully connected to Redis", pong)
 
-	router := gin.Default()
-	router.Use(middlewares.CustomLogger())
-	router.Use(middlewares.RedisMiddleware(rdb))
+	cacheTTL, err := strconv.Atoi(utils.LoadEnv("REDIS_CACHE_TTL"))
+	if err != nil {
+		log.Fatalf("Invalid REDIS_CACHE_TTL: %v", err)
+	}
+	responseCache := cache.NewRedisCache(rdb, time.Duration(cacheTTL)*time.Second)
+
+	// Wire repositories, services and handlers
+	locales := i18n.LoadLocales()
+	aboutService := aboutservices.NewService(aboutrepositories.NewRepository(db), responseCache, locales)
+	store := storage.Load()
+	imageLimits := images.LoadLimits()
+	projectRepo := projectrepositories.NewRepository(db)
+	projectService := projectservices.NewService(projectRepo, responseCache, locales, store, imageLimits,
+		githosting.NewClient(githosting.LoadConfig()))
+	contactService := contactservices.NewService(contactrepositories.NewRepository(db), responseCache,
+		hooks.SMTPMailer{}, utils.LoadEnv("EMAIL_TARGET"))
+	// Projects created before slugs existed get one; a failure only leaves them reachable by ID until the next start
+	if err := projectService.BackfillSlugs(ctx); err != nil {
+		log.Printf("Error generating project slugs: %v", err)
+	}
+	linkCheckJob := projectservices.NewLinkCheckJob(projectRepo, linkcheck.NewChecker(linkcheck.LoadConfig()),
+		hooks.SMTPMailer{}, projectservices.LoadLinkCheckPolicy())
+	retentionJob := jobs.NewRetentionJob(db, responseCache, jobs.LoadRetentionPolicy())
 
-	routes.SetupAboutRoutes(router)
-	routes.SetupProjectRoutes(router)
-	routes.SetupContactRoutes(router)
+	// Start background jobs
+	scheduler := jobs.NewScheduler()
+	scheduler.Every("contact-retention", utils.LoadEnvDuration("RETENTION_INTERVAL", 24*time.Hour), retentionJob.Run)
+	scheduler.Every("project-publishing", utils.LoadEnvDuration("PUBLISH_INTERVAL", time.Minute), projectService.PublishScheduled)
+	scheduler.Every("link-check", utils.LoadEnvDuration("LINK_CHECK_INTERVAL", 24*time.Hour), linkCheckJob.Run)
+	scheduler.Every("repository-sync", utils.LoadEnvDuration("REPOSITORY_SYNC_INTERVAL", time.Hour), projectService.SyncRepositories)
+	scheduler.Start(ctx)
+
+	if utils.LoadEnvDefault("ADMIN_API_KEY", "") == "" {
+		log.Println("ADMIN_API_KEY not set, admin endpoints disabled")
+	}
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(middlewares.RequestID())
+	router.Use(middlewares.Tracing())
+	router.Use(middlewares.Metrics())
+	router.Use(middlewares.CustomLogger(logger.NewRedactor(logConfig)))
+	if utils.LoadEnvBool("OPENAPI_VALIDATE_RESPONSES", false) {
+		// Development only: every response is buffered and checked against the API documentation
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("OPENAPI_VALIDATE_RESPONSES is a development switch and cannot be used with GIN_MODE=release")
+		}
+		log.Println("Validating responses against the OpenAPI document")
+		router.Use(middlewares.ValidateResponse(routes.Spec()))
+	}
+	router.Use(middlewares.ErrorHandler())
+	router.Use(middlewares.Recovery())
+
+	routes.SetupAboutRoutes(router, aboutcontrollers.NewHandler(aboutService, locales))
+	routes.SetupProjectRoutes(router, projectcontrollers.NewHandler(projectService, locales, imageLimits))
+	routes.SetupContactRoutes(router, contactcontrollers.NewHandler(contactService, retentionJob))
+	routes.SetupMetricsRoutes(router)
+	routes.SetupOpenAPIRoutes(router)
+	routes.SetupUploadRoutes(router, store)
+	router.NoRoute(middlewares.NoRoute)
+	router.NoMethod(middlewares.NoMethod)
+
+	// Refuse to start when the routes and the API documentation disagree
+	if err := routes.CheckSpec(router); err != nil {
+		log.Fatal(err)
+	}
 
 	log.Println(http.ListenAndServe(":"+utils.LoadEnv("GO_PORT"), router))
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+// Package markdown renders the Markdown content of the portfolio into sanitized HTML.
+//
+// Content is rendered with goldmark and then sanitized with bluemonday, so that the HTML can be
+// inserted into the website as is: raw HTML and unsafe URLs are removed, headings get an ID and an
+// anchor link to themselves, fenced code blocks keep their "language-*" class for syntax
+// highlighting, and links to other sites get rel="noopener nofollow".
+package markdown
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// externalLinkRel is the rel attribute of links to other sites.
+const externalLinkRel = "noopener nofollow"
+
+// anchorClass is the class of the anchor links added to headings.
+const anchorClass = "anchor"
+
+// renderer converts Markdown into HTML. Raw HTML in the source is omitted.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.Linkify),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(util.Prioritized(transformer{}, 100)),
+	),
+)
+
+// policy removes everything from the rendered HTML that is not produced by safe Markdown.
+var policy = newPolicy()
+
+// newPolicy returns the sanitizing policy of the rendered HTML.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// The rel attribute is set by the renderer, so the policy must not rewrite it
+	p.RequireNoFollowOnLinks(false)
+	p.AllowAttrs("rel").Matching(regexp.MustCompile("^" + externalLinkRel + "$")).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile("^" + anchorClass + "$")).OnElements("a")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	return p
+}
+
+// Render converts source into sanitized HTML.
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// transformer adds the anchor links to headings and the rel attribute to external links.
+type transformer struct{}
+
+func (transformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			if id, ok := n.AttributeString("id"); ok {
+				anchor := ast.NewLink()
+				anchor.Destination = append([]byte("#"), id.([]byte)...)
+				anchor.SetAttributeString("class", []byte(anchorClass))
+				anchor.AppendChild(anchor, ast.NewString([]byte("#")))
+				n.AppendChild(n, anchor)
+			}
+		case *ast.Link:
+			if isExternal(n.Destination) {
+				n.SetAttributeString("rel", []byte(externalLinkRel))
+			}
+		case *ast.AutoLink:
+			if isExternal(n.URL(reader.Source())) {
+				n.SetAttributeString("rel", []byte(externalLinkRel))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// isExternal reports whether destination points to another site.
+func isExternal(destination []byte) bool {
+	u, err := url.Parse(string(destination))
+	return err == nil && u.Host != ""
+}
@@ -0,0 +1,43 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"raw HTML block", "<script>alert(1)</script>", "\n"},
+		{"inline raw HTML", "Hello <img src=x onerror=alert(1)> world", "<p>Hello  world</p>\n"},
+		{"unsafe URL", "[x](javascript:alert(1))", "<p>x</p>\n"},
+		{"external link", "[x](https://example.com)", `<p><a href="https://example.com" rel="noopener nofollow">x</a></p>` + "\n"},
+		{"external autolink", "https://example.com", `<p><a href="https://example.com" rel="noopener nofollow">https://example.com</a></p>` + "\n"},
+		{"internal link", "[x](/projects/1)", `<p><a href="/projects/1">x</a></p>` + "\n"},
+		{"heading anchor", "## Hello World", `<h2 id="hello-world">Hello World<a href="#hello-world" class="anchor">#</a></h2>` + "\n"},
+		{"code language", "```go\nx\n```", `<pre><code class="language-go">x` + "\n</code></pre>\n"},
+		{"unsafe code class", "```go\"><script>\nx\n```", "<pre><code>x\n</code></pre>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.source)
+			if err != nil {
+				t.Fatalf("Render(%q) returned error: %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderKeepsOnlyItsOwnRel(t *testing.T) {
+	// A rel attribute can only come from the renderer, never from the source
+	got, err := Render(`<a href="https://example.com" rel="opener">x</a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "<p>x</p>\n" {
+		t.Errorf("Render() = %q, want the raw link removed", got)
+	}
+}
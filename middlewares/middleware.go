@@ -3,14 +3,31 @@ package middlewares
 
 import (
 	"bytes"
-	"io/ioutil"
-	"log"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/config/logger"
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/config/tracing"
+	"github.com/EkoAgustina/go-ms-portfolio/openapi"
 	"github.com/EkoAgustina/go-ms-portfolio/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ValidateApiKey checks for the presence and validity of an API key in the request header.
@@ -23,90 +40,482 @@ func ValidateApiKey() gin.HandlerFunc {
 		apikey := c.GetHeader("x-api-key")
 
 		if apikey == "" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"responseCode": http.StatusForbidden,
-				"error": "apikey required",
-			})
+			_ = c.Error(apperrors.New(http.StatusForbidden, apperrors.CodeAPIKeyRequired, "apikey required"))
 			c.Abort()
 			return
 		}
 
 		if apikey != utils.LoadEnv("API_KEY") {
-			c.JSON(http.StatusForbidden, gin.H{
-				"responseCode": http.StatusForbidden,
-				"error": "Invalid apikey",
-			})
+			_ = c.Error(apperrors.New(http.StatusForbidden, apperrors.CodeAPIKeyInvalid, "Invalid apikey"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ValidateAdminKey checks the admin key sent in the x-admin-key request header.
+// Admin endpoints expose or change data that the public API key must not reach,
+// so they require ADMIN_API_KEY in addition to the regular API key. The key is read once, when the
+// middleware is created; without it, every admin request is rejected.
+// If the admin key is missing or invalid, it responds with a 403 Forbidden status and aborts the request.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ValidateAdminKey() gin.HandlerFunc {
+	expected := utils.LoadEnvDefault("ADMIN_API_KEY", "")
+	return func(c *gin.Context) {
+		adminKey := c.GetHeader("x-admin-key")
+
+		if adminKey == "" {
+			_ = c.Error(apperrors.New(http.StatusForbidden, apperrors.CodeAdminKeyRequired, "admin key required"))
 			c.Abort()
 			return
 		}
 
+		if expected == "" || subtle.ConstantTimeCompare([]byte(adminKey), []byte(expected)) != 1 {
+			_ = c.Error(apperrors.New(http.StatusForbidden, apperrors.CodeAdminKeyInvalid, "Invalid admin key"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ValidateAdminKeyWhen applies ValidateAdminKey only to requests whose query parameter flag is true,
+// so that a public endpoint can offer admin-only views, e.g. GET /api/v1/projects?drafts=true.
+// Other requests proceed without an admin key.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ValidateAdminKeyWhen(flag string) gin.HandlerFunc {
+	validate := ValidateAdminKey()
+	return func(c *gin.Context) {
+		if enabled, _ := strconv.ParseBool(c.Query(flag)); enabled {
+			validate(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestIDHeader is the header used to propagate the request correlation ID.
+const requestIDHeader = "X-Request-ID"
+
+// Deprecated marks a route as a deprecated alias of successor.
+// It sets the Deprecation header (RFC 9745) to deprecatedAt, the Sunset header (RFC 8594)
+// to sunsetAt and a Link header pointing to the successor route, then lets the request proceed.
+// A zero deprecatedAt is sent as "true" and a zero sunsetAt leaves the Sunset header out.
+//
+// Parameters:
+// - deprecatedAt: When the route was deprecated.
+// - sunsetAt: When the route will stop responding.
+// - successor: Path of the route that replaces it, e.g. /api/v1/contacts/:id, with path parameters filled in.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func Deprecated(deprecatedAt time.Time, sunsetAt time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !deprecatedAt.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+		} else {
+			c.Header("Deprecation", "true")
+		}
+		if !sunsetAt.IsZero() {
+			c.Header("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+		}
+		link := successor
+		for _, param := range c.Params {
+			link = strings.ReplaceAll(link, ":"+param.Key, url.PathEscape(param.Value))
+		}
+		c.Header("Link", "<"+link+">; rel=\"successor-version\"")
 		c.Next()
 	}
 }
 
-// CustomWriter is a custom ResponseWriter that captures the response body for logging.
+// RequestID assigns a correlation ID to every request.
+// It reuses the X-Request-ID request header when it holds a valid ID and generates a new one otherwise.
+// The ID is returned in the X-Request-ID response header, stored in the Gin context under "requestId"
+// and attached to the request context so that every log record of the request carries it.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("requestId", requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		c.Header(requestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to reuse.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random 128-bit request ID encoded as hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ErrorHandler renders the last error added to the Gin context with c.Error as an
+// RFC 7807 application/problem+json response carrying a stable error code and the request ID.
+// Server errors are logged together with their cause, which is never sent to the client.
+// Nothing is rendered when the handler has already written a response.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperrors.From(last.Err)
+		if appErr.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "Request failed", "code", appErr.Code, "error", appErr)
+		}
+		writeProblem(c, appErr)
+	}
+}
+
+// writeProblem renders appErr as an RFC 7807 application/problem+json response.
+func writeProblem(c *gin.Context, appErr *apperrors.Error) {
+	problem := apperrors.NewProblem(appErr, c.Request.URL.Path, c.GetString("requestId"))
+	c.Header("Content-Type", apperrors.ProblemContentType)
+	c.JSON(appErr.Status, problem)
+}
+
+// Recovery turns a panic in a handler into an internal error rendered by ErrorHandler.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		_ = c.Error(apperrors.Internal(fmt.Errorf("panic: %v", recovered)))
+		c.Abort()
+	})
+}
+
+// NoRoute reports requests that match no route as a 404 problem.
+func NoRoute(c *gin.Context) {
+	_ = c.Error(apperrors.New(http.StatusNotFound, apperrors.CodeRouteNotFound, "No route matches "+c.Request.URL.Path))
+}
+
+// NoMethod reports requests whose route does not support the method as a 405 problem.
+func NoMethod(c *gin.Context) {
+	_ = c.Error(apperrors.New(http.StatusMethodNotAllowed, apperrors.CodeMethodNotAllowed, "Method "+c.Request.Method+" is not allowed on "+c.Request.URL.Path))
+}
+
+// CustomWriter is a custom ResponseWriter that captures the beginning of the response body for logging.
 type CustomWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body  *bytes.Buffer
+	limit int
+	size  int
 }
 
-// Write writes the response body and captures it in the CustomWriter.
-func (w CustomWriter) Write(b []byte) (int, error) {
-	w.body.Write(b) // Save the response body
+// Write writes the response body and captures up to limit bytes of it in the CustomWriter.
+func (w *CustomWriter) Write(b []byte) (int, error) {
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		w.body.Write(b[:remaining]) // Save the beginning of the response body
+	}
+	w.size += len(b)
 	return w.ResponseWriter.Write(b) // Send the response to the client
 }
 
-// CustomLogger logs request and response details, including headers, body, and execution time.
-// It wraps the request processing to capture data for logging purposes.
+// WriteString writes a string response body through Write so that it is captured as well.
+func (w *CustomWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// CustomLogger writes one structured log record per request, including method, path, status,
+// execution time, headers and bodies. Sensitive headers and JSON fields are redacted, and bodies
+// larger than the configured limit are logged by size only.
+// Server errors are logged at error level, client errors at warn level and everything else at info level.
+//
+// Parameters:
+// - redactor: The redaction settings applied to headers and bodies.
 //
 // Returns a gin.HandlerFunc that can be used as middleware.
-func CustomLogger() gin.HandlerFunc {
+func CustomLogger(redactor *logger.Redactor) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Log request headers
-		log.Printf("Request Headers: %v", c.Request.Header)
+		// Start timer to record the request duration
+		startTime := time.Now()
 
-		// Log request body
-		bodyBytes, err := ioutil.ReadAll(c.Request.Body)
+		// Read the beginning of the request body and put it back in front of the rest
+		limit := redactor.BodyLimit()
+		requestBody, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
 		if err != nil {
-			log.Printf("Error reading body: %v", err)
-			c.AbortWithStatus(http.StatusBadRequest)
+			// ErrorHandler comes after the logger, so the problem is rendered here
+			appErr := apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Error reading request body")
+			_ = c.Error(appErr)
+			writeProblem(c, appErr)
+			c.Abort()
 			return
 		}
-		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
-		log.Printf("Request Body: %s", string(bodyBytes))
-
-		// Start timer to record the request duration
-		startTime := time.Now()
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+		requestSize := len(requestBody)
+		if c.Request.ContentLength > int64(requestSize) {
+			requestSize = int(c.Request.ContentLength)
+		}
 
 		// Capture response using custom writer
-		customWriter := &CustomWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		customWriter := &CustomWriter{body: &bytes.Buffer{}, limit: limit + 1, ResponseWriter: c.Writer}
 		c.Writer = customWriter
 
 		// Proceed to the next handler
 		c.Next()
 
-		// Log response headers
-		log.Printf("Response Headers: %v", c.Writer.Header())
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("durationMs", float64(time.Since(startTime).Microseconds())/1000),
+			slog.String("clientIp", c.ClientIP()),
+			slog.Group("request",
+				slog.Any("headers", redactor.Headers(c.Request.Header)),
+				slog.Any("body", redactor.Body(c.ContentType(), requestBody, requestSize)),
+			),
+			slog.Group("response",
+				slog.Any("headers", redactor.Headers(c.Writer.Header())),
+				slog.Any("body", redactor.Body(c.Writer.Header().Get("Content-Type"), customWriter.body.Bytes(), customWriter.size)),
+			),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "Request processed", attrs...)
+	}
+}
+
+// readCloser combines a reader with the closer of the original request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Metrics records the number and latency of handled requests by method, route and status code.
+// Requests that match no route are reported with the route "unmatched" to keep the label set bounded.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
 
-		// Log response body
-		log.Printf("Response Body: %s", customWriter.body.String())
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
 
-		// Log execution time
-		duration := time.Since(startTime)
-		log.Printf("Request processed in %s", duration)
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(startTime).Seconds())
 	}
 }
 
-// RedisMiddleware sets up the Redis client in the context for later use in handlers.
-// It allows handlers to access the Redis client without needing to pass it explicitly.
+// ValidateMetricsToken checks the bearer token sent to the metrics endpoint.
+// The metrics token is separate from the API key so that the scraper cannot reach the API.
+// If the token is missing or invalid, it responds with a 401 Unauthorized status and aborts the request.
 //
 // Parameters:
-// - rdb: The Redis client instance to set in the context.
+// - token: The expected bearer token.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ValidateMetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			_ = c.Error(apperrors.New(http.StatusUnauthorized, apperrors.CodeMetricsTokenInvalid, "Invalid metrics token"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Tracing starts a server span for every request.
+// The trace context of incoming traceparent headers is continued, and the span is stored
+// in the request context so that database, cache and email spans become its children.
 //
 // Returns a gin.HandlerFunc that can be used as middleware.
-func RedisMiddleware(rdb *redis.Client) gin.HandlerFunc {
+func Tracing() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("redis", rdb)
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if err := c.Errors.Last(); err != nil {
+			span.RecordError(err)
+		}
+	}
+}
+
+// maxValidatedBody is the largest request body ValidateRequest accepts, in bytes.
+const maxValidatedBody = 1 << 20
+
+// maxValidatedResponse is the largest response body ValidateResponse validates, in bytes.
+const maxValidatedResponse = 1 << 20
+
+// ValidateRequest validates the request against the operation documented for the matched route
+// in the OpenAPI document, before the request reaches the controller.
+// Invalid path, query or header parameters are rejected with a 400 Bad Request status,
+// a missing or malformed JSON body with a 400 Bad Request status, a body larger than 1 MiB
+// with a 413 Request Entity Too Large status, and a body that does not match the request schema
+// with a 422 Unprocessable Entity status. Every invalid field is listed in the problem.
+// Routes that are not documented are passed through unchanged.
+//
+// Parameters:
+// - doc: The OpenAPI document of the API.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ValidateRequest(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, route := c.Request.Method, c.FullPath()
+		op, ok := doc.Lookup(method, route)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if fields := doc.ValidateParameters(method, route, c.Params, c.Request.URL.Query(), c.Request.Header); len(fields) > 0 {
+			_ = c.Error(&apperrors.Error{
+				Status: http.StatusBadRequest,
+				Code:   apperrors.CodeInvalidParameter,
+				Detail: "Request contains invalid parameters",
+				Fields: fields,
+			})
+			c.Abort()
+			return
+		}
+
+		if op.Request == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxValidatedBody))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				_ = c.Error(apperrors.Wrap(err, http.StatusRequestEntityTooLarge, apperrors.CodeBodyTooLarge,
+					fmt.Sprintf("Request body must not exceed %d bytes", maxValidatedBody)))
+			} else {
+				_ = c.Error(apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Error reading request body"))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fields, err := doc.ValidateBody(method, route, body)
+		switch {
+		case errors.Is(err, openapi.ErrMissingBody):
+			_ = c.Error(apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Request body is required"))
+			c.Abort()
+		case err != nil:
+			_ = c.Error(apperrors.Wrap(err, http.StatusBadRequest, apperrors.CodeInvalidBody, "Invalid request body format"))
+			c.Abort()
+		case len(fields) > 0:
+			_ = c.Error(&apperrors.Error{
+				Status: http.StatusUnprocessableEntity,
+				Code:   apperrors.CodeValidationFailed,
+				Detail: "Request body contains invalid fields",
+				Fields: fields,
+			})
+			c.Abort()
+		default:
+			c.Next()
+		}
+	}
+}
+
+// ValidateResponse checks every JSON response against the schema documented for its route and status
+// in the OpenAPI document, and logs an error listing the violations. The response is sent unchanged.
+// It is meant for development, where it shows when handlers and models drift from the document.
+//
+// Parameters:
+// - doc: The OpenAPI document of the API.
+//
+// Returns a gin.HandlerFunc that can be used as middleware.
+func ValidateResponse(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &CustomWriter{body: &bytes.Buffer{}, limit: maxValidatedResponse + 1, ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if c.FullPath() == "" || writer.size > maxValidatedResponse {
+			return
+		}
+		violations := doc.ValidateResponse(c.Request.Method, c.FullPath(), writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if len(violations) > 0 {
+			slog.ErrorContext(c.Request.Context(), "Response does not match the OpenAPI document",
+				slog.String("method", c.Request.Method),
+				slog.String("route", c.FullPath()),
+				slog.Int("status", writer.Status()),
+				slog.Any("violations", violations),
+			)
+		}
 	}
 }
@@ -0,0 +1,173 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/EkoAgustina/go-ms-portfolio/config/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	// The keys are read from the environment alone, without an .env file
+	os.Setenv("ENV_FILE", os.DevNull)
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// ok is the handler behind the middlewares under test.
+func ok(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"responseCode": http.StatusOK})
+}
+
+func TestErrorHandler(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler())
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(apperrors.Database(errors.New("pq: deadlock detected")))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.JSON(http.StatusAccepted, gin.H{"responseCode": http.StatusAccepted})
+		_ = c.Error(errors.New("logged only"))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	request.Header.Set(requestIDHeader, "request-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, apperrors.ProblemContentType) {
+		t.Errorf("Content-Type = %q, want %q", got, apperrors.ProblemContentType)
+	}
+	var problem apperrors.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body is not a problem document: %v", err)
+	}
+	if recorder.Code != http.StatusInternalServerError || problem.Status != http.StatusInternalServerError ||
+		problem.Code != apperrors.CodeDatabaseError || problem.Instance != "/fail" || problem.RequestID != "request-1" {
+		t.Errorf("response = %d %+v, want a database error problem for /fail and request-1", recorder.Code, problem)
+	}
+	// The cause is logged, never sent
+	if strings.Contains(recorder.Body.String(), "deadlock") {
+		t.Errorf("body leaks the cause: %s", recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if recorder.Code != http.StatusAccepted || strings.Contains(recorder.Body.String(), "about:blank") {
+		t.Errorf("response = %d %s, want the response of the handler untouched", recorder.Code, recorder.Body)
+	}
+}
+
+// failingBody is a request body whose reads fail.
+type failingBody struct{}
+
+func (failingBody) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+func (failingBody) Close() error             { return nil }
+
+func TestCustomLoggerBodyReadError(t *testing.T) {
+	// ErrorHandler runs after the logger, as in main.go
+	router := gin.New()
+	router.Use(CustomLogger(logger.NewRedactor(logger.Config{BodyLimit: 64})), ErrorHandler())
+	router.POST("/test", ok)
+
+	request := httptest.NewRequest(http.MethodPost, "/test", nil)
+	request.Body = failingBody{}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var problem apperrors.Problem
+	_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+	if recorder.Code != http.StatusBadRequest || problem.Code != apperrors.CodeInvalidBody {
+		t.Errorf("response = %d %s, want a 400 invalid_body problem", recorder.Code, recorder.Body)
+	}
+}
+
+func TestValidateAdminKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		header string
+		status int
+		code   apperrors.Code
+	}{
+		{"valid key", "admin-key", "admin-key", http.StatusOK, ""},
+		{"missing key", "admin-key", "", http.StatusForbidden, apperrors.CodeAdminKeyRequired},
+		{"wrong key", "admin-key", "admin-kez", http.StatusForbidden, apperrors.CodeAdminKeyInvalid},
+		{"prefix of the key", "admin-key", "admin", http.StatusForbidden, apperrors.CodeAdminKeyInvalid},
+		{"unset key", "", "anything", http.StatusForbidden, apperrors.CodeAdminKeyInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_API_KEY", tt.env)
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/admin", ValidateAdminKey(), ok)
+
+			request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				request.Header.Set("x-admin-key", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			var problem apperrors.Problem
+			_ = json.Unmarshal(recorder.Body.Bytes(), &problem)
+			if recorder.Code != tt.status || problem.Code != tt.code {
+				t.Errorf("response = %d %s, want %d with code %q", recorder.Code, recorder.Body, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateAdminKeyReadsKeyOnce(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "admin-key")
+	router := gin.New()
+	router.GET("/admin", ValidateAdminKey(), ok)
+	// A later change of the environment does not affect the running middleware
+	t.Setenv("ADMIN_API_KEY", "")
+
+	request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	request.Header.Set("x-admin-key", "admin-key")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+}
+
+func TestValidateAdminKeyWhen(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "admin-key")
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/projects", ValidateAdminKeyWhen("drafts"), ok)
+
+	tests := []struct {
+		target string
+		key    string
+		status int
+	}{
+		{"/projects", "", http.StatusOK},
+		{"/projects?drafts=false", "", http.StatusOK},
+		{"/projects?drafts=true", "", http.StatusForbidden},
+		{"/projects?drafts=1", "wrong", http.StatusForbidden},
+		{"/projects?drafts=true", "admin-key", http.StatusOK},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.key != "" {
+			request.Header.Set("x-admin-key", tt.key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != tt.status {
+			t.Errorf("GET %s with key %q = %d, want %d", tt.target, tt.key, recorder.Code, tt.status)
+		}
+	}
+}
@@ -2,18 +2,167 @@
 package aboutmodels
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Skill levels, from the least to the most experienced.
+const (
+	LevelBeginner     = "beginner"
+	LevelIntermediate = "intermediate"
+	LevelAdvanced     = "advanced"
+	LevelExpert       = "expert"
+)
+
 // About represents an "About" entity in the database.
 // It includes fields for storing information related to the "About" section of a portfolio.
+// The portfolio has a single current About document; every save is recorded as an AboutRevision.
 //
 // Fields:
 // - ID: Auto-generated ID for the about entry (inherited from gorm.Model).
 // - CreatedAt: Timestamp for when the about entry was created (inherited from gorm.Model).
 // - UpdatedAt: Timestamp for when the about entry was last updated (inherited from gorm.Model).
-// - Content: The content of the "About" section, marked as required for validation.
+// - Content: The bio of the "About" section in Markdown, marked as required for validation.
+// - ContentHTML: Content rendered into sanitized HTML; not stored, rendered when the document is read.
+// - Headline: A one-line professional title, e.g. "Backend Engineer".
+// - Summary: A short introduction shown next to the headline.
+// - Location: Where the owner of the portfolio is based.
+// - AvatarURL: The URL of the profile picture.
+// - Revision: The number of the revision the document was last saved as.
+// - Locale: The locale of the translated fields of a read; not stored.
+// - SocialLinks, Skills, Experience, Education: Ordered collections of the profile.
+// - Translations: Content in the other supported locales; Content holds the default locale.
 type About struct {
 	gorm.Model
-	Content string `json:"content" binding:"required"` // Content of the "About" section
+	Revision     int                `json:"revision" openapi:"readOnly"`                // Number of the current revision
+	Content      string             `json:"content" binding:"required"`                 // Bio of the "About" section, in Markdown
+	ContentHTML  string             `json:"contentHtml" gorm:"-" openapi:"readOnly"`    // Content rendered into sanitized HTML
+	Headline     string             `json:"headline" binding:"max=160"`                 // One-line professional title
+	Summary      string             `json:"summary" binding:"max=500"`                  // Short introduction
+	Location     string             `json:"location" binding:"max=120"`                 // Where the owner is based
+	AvatarURL    string             `json:"avatarUrl" binding:"omitempty,url,max=2048"` // URL of the profile picture
+	SocialLinks  []SocialLink       `json:"socialLinks" binding:"max=20,dive"`          // Links to social profiles
+	Skills       []Skill            `json:"skills" binding:"max=100,dive"`              // Skills with their level
+	Experience   []Experience       `json:"experience" binding:"max=50,dive"`           // Work history, most recent first
+	Education    []Education        `json:"education" binding:"max=20,dive"`            // Education history, most recent first
+	Locale       string             `json:"locale" gorm:"-" openapi:"readOnly"`         // Locale of the translated fields
+	Translations []AboutTranslation `json:"translations" binding:"max=20,dive"`         // Content in the other locales
+}
+
+// AboutTranslation holds the translated fields of the About document in one locale.
+// An empty field falls back to the next locale of the fallback chain.
+type AboutTranslation struct {
+	ID      uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	AboutID uint   `json:"-" gorm:"uniqueIndex:idx_about_translations_locale"`
+	Locale  string `json:"locale" gorm:"type:varchar(35);uniqueIndex:idx_about_translations_locale" binding:"required,max=35"` // BCP 47 tag, e.g. id
+	Content string `json:"content"`                                                                                            // Bio in Markdown
+}
+
+// SocialLink is a link to a profile on another site.
+type SocialLink struct {
+	ID       uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	AboutID  uint   `json:"-" gorm:"index"`
+	Platform string `json:"platform" binding:"required,max=40"`  // Name of the site, e.g. GitHub
+	URL      string `json:"url" binding:"required,url,max=2048"` // URL of the profile
+	Position int    `json:"position" openapi:"readOnly"`         // Order in the profile, set from the order of the request
+}
+
+// Skill is a skill of the portfolio owner.
+type Skill struct {
+	ID       uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	AboutID  uint   `json:"-" gorm:"index"`
+	Name     string `json:"name" binding:"required,max=80"`                                       // Name of the skill, e.g. Go
+	Level    string `json:"level" binding:"required,oneof=beginner intermediate advanced expert"` // Proficiency level
+	Category string `json:"category" binding:"max=60"`                                            // Group of the skill, e.g. Backend
+	Position int    `json:"position" openapi:"readOnly"`                                          // Order in the profile, set from the order of the request
+}
+
+// Experience is a position in the work history.
+// Dates are months in the YYYY-MM format; a null end date means the position is current.
+type Experience struct {
+	ID         uint     `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	AboutID    uint     `json:"-" gorm:"index"`
+	Role       string   `json:"role" binding:"required,max=120"`                                            // Job title
+	Company    string   `json:"company" binding:"required,max=120"`                                         // Employer
+	Location   string   `json:"location" binding:"max=120"`                                                 // Where the job was based
+	StartDate  string   `json:"startDate" binding:"required,datetime=2006-01"`                              // First month, YYYY-MM
+	EndDate    *string  `json:"endDate" binding:"omitempty,datetime=2006-01"`                               // Last month, YYYY-MM, or null if current
+	Highlights []string `json:"highlights" gorm:"type:jsonb;serializer:json" binding:"max=10,dive,max=300"` // Achievements in the role
+	Position   int      `json:"position" openapi:"readOnly"`                                                // Order in the profile, set from the order of the request
+}
+
+// Education is an entry in the education history.
+// Dates are months in the YYYY-MM format; a null end date means the studies are ongoing.
+type Education struct {
+	ID          uint    `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	AboutID     uint    `json:"-" gorm:"index"`
+	Institution string  `json:"institution" binding:"required,max=160"`        // School or university
+	Degree      string  `json:"degree" binding:"max=120"`                      // Degree obtained, e.g. Bachelor of Science
+	Field       string  `json:"field" binding:"max=120"`                       // Field of study
+	StartDate   string  `json:"startDate" binding:"required,datetime=2006-01"` // First month, YYYY-MM
+	EndDate     *string `json:"endDate" binding:"omitempty,datetime=2006-01"`  // Last month, YYYY-MM, or null if ongoing
+	Description string  `json:"description" binding:"max=500"`                 // Details such as honors or thesis
+	Position    int     `json:"position" openapi:"readOnly"`                   // Order in the profile, set from the order of the request
+}
+
+// AboutRevision is an immutable snapshot of the About document, recorded every time it is saved.
+type AboutRevision struct {
+	ID             uint      `json:"-" gorm:"primaryKey"`
+	Number         int       `json:"number" gorm:"uniqueIndex"`                            // Sequential number, starting at 1
+	Author         string    `json:"author" gorm:"type:varchar(100)"`                      // Who saved the revision
+	RolledBackFrom *int      `json:"rolledBackFrom"`                                       // Revision restored by a rollback, if any
+	CreatedAt      time.Time `json:"createdAt"`                                            // When the revision was saved
+	Snapshot       *About    `json:"snapshot,omitempty" gorm:"type:jsonb;serializer:json"` // The document as saved; omitted in listings
+}
+
+// Change is a difference between two revisions of the About document.
+type Change struct {
+	Path string      `json:"path"`           // Field that changed, e.g. skills[0].level
+	Op   string      `json:"op"`             // added, removed or changed
+	From interface{} `json:"from,omitempty"` // Value in the older revision
+	To   interface{} `json:"to,omitempty"`   // Value in the newer revision
+}
+
+// Change operations.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Normalize numbers the entries of every collection in the order they were sent, so that the
+// profile is returned in the same order, and replaces missing lists with empty ones.
+func (a *About) Normalize() {
+	if a.SocialLinks == nil {
+		a.SocialLinks = []SocialLink{}
+	}
+	if a.Skills == nil {
+		a.Skills = []Skill{}
+	}
+	if a.Experience == nil {
+		a.Experience = []Experience{}
+	}
+	if a.Education == nil {
+		a.Education = []Education{}
+	}
+	if a.Translations == nil {
+		a.Translations = []AboutTranslation{}
+	}
+
+	for i := range a.SocialLinks {
+		a.SocialLinks[i].Position = i
+	}
+	for i := range a.Skills {
+		a.Skills[i].Position = i
+	}
+	for i := range a.Experience {
+		a.Experience[i].Position = i
+		if a.Experience[i].Highlights == nil {
+			a.Experience[i].Highlights = []string{}
+		}
+	}
+	for i := range a.Education {
+		a.Education[i].Position = i
+	}
 }
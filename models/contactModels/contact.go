@@ -1,9 +1,19 @@
 package contactmodels
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Contact statuses used to triage incoming messages.
+// The retention job applies different rules depending on the status.
+const (
+	StatusNew      = "new"      // Message has not been reviewed yet
+	StatusSpam     = "spam"     // Message was flagged as spam
+	StatusArchived = "archived" // Message was handled and archived
+)
+
 // Contact represents a contact entity in the database.
 // It includes fields for storing information about a contact message from a user.
 //
@@ -15,10 +25,21 @@ import (
 // - Email: The email address of the person who contacted.
 // - Subject: The subject of the contact message.
 // - Message: The content of the contact message.
+// - Status: The triage status of the message (new, spam or archived).
+// - ArchivedAt: Timestamp for when the message was last archived; null unless it is archived.
+// - AnonymizedAt: Timestamp for when the personal data of the message was removed.
 type Contact struct {
 	gorm.Model
-	Name    string `json:"name"`    // Name of the person who contacted
-	Email   string `json:"email"`   // Email address of the person who contacted
-	Subject string `json:"subject"` // Subject of the contact message
-	Message string `json:"message"` // Content of the contact message
+	Name         string     `json:"name"`                                                                // Name of the person who contacted
+	Email        string     `json:"email" binding:"omitempty,email"`                                     // Email address of the person who contacted
+	Subject      string     `json:"subject"`                                                             // Subject of the contact message
+	Message      string     `json:"message"`                                                             // Content of the contact message
+	Status       string     `json:"status" gorm:"type:varchar(16);default:new;index" openapi:"readOnly"` // Triage status of the message
+	ArchivedAt   *time.Time `json:"archivedAt" openapi:"readOnly"`                                       // When the message was archived
+	AnonymizedAt *time.Time `json:"anonymizedAt" openapi:"readOnly"`                                     // When the message was anonymized
+}
+
+// ContactStatus is the request body used to change the status of a contact message.
+type ContactStatus struct {
+	Status string `json:"status" binding:"required,oneof=new spam archived"` // New triage status
 }
@@ -1,9 +1,40 @@
 package projectmodels
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Publication statuses of projects. Public reads only return published projects.
+const (
+	StatusDraft     = "draft"     // Project is only visible to admins; published by the scheduler once PublishAt has passed, if set
+	StatusPublished = "published" // Project is public
+	StatusArchived  = "archived"  // Project was withdrawn and is only visible to admins
+)
+
+// Development stages of projects, unrelated to their publication status.
+const (
+	LifecycleActive     = "active"     // Project is under active development
+	LifecycleMaintained = "maintained" // Project only receives fixes
+	LifecycleArchived   = "archived"   // Project is no longer developed
+)
+
+// Types of the links of projects.
+const (
+	LinkRepo    = "repo"    // Source code repository
+	LinkDemo    = "demo"    // Live demo
+	LinkArticle = "article" // Article or blog post about the project
+	LinkVideo   = "video"   // Video presentation
+)
+
+// Types of the sections of the write-up of projects.
+const (
+	SectionProblem  = "problem"  // The problem the project solves
+	SectionSolution = "solution" // How the project solves it
+	SectionResults  = "results"  // What the project achieved
+)
+
 // Project represents a project entity in the database.
 // It includes fields for storing project details such as title, description, image, and repository link.
 //
@@ -11,16 +42,193 @@ import (
 // - ID: Auto-generated ID for the project (inherited from gorm.Model).
 // - CreatedAt: Timestamp for when the project was created (inherited from gorm.Model).
 // - UpdatedAt: Timestamp for when the project was last updated (inherited from gorm.Model).
-// - ImageTitle: The title of the project's image.
-// - Image: The URL or path to the project's image.
+// - ImageTitle: The title of the project's image; replaced on reads by the alt text of the cover of the gallery.
+// - Image: The URL or path to the project's image, given as a URL hosted elsewhere; replaced on reads by the cover of the gallery.
+// - ImageWidth, ImageHeight, ImageVariants, ImageBlurHash, ImageColor: The dimensions, resized copies and placeholders of the cover; not stored, copied from the cover on reads.
 // - ProjectTitle: The title of the project.
-// - ProjectDescription: A brief description of the project.
+// - Slug: The unique human-readable identifier of the project in URLs, generated from ProjectTitle; the previous slugs are kept in ProjectSlug.
+// - ProjectDescription: A brief description of the project in Markdown, at most 270 characters long.
+// - ProjectDescriptionHTML: ProjectDescription rendered into sanitized HTML; not stored, rendered when the project is read.
 // - RepositoryLink: A link to the project's repository (e.g., GitHub).
+// - Repository: Stars, forks, language, topics and last push of the repository, synced from GitHub or GitLab by a background job.
+// - DemoURL, DocumentationURL: Links to the live demo and the documentation of the project.
+// - Role: Our role in the project, e.g. Lead developer.
+// - TeamSize: The number of people who worked on the project; 0 when unknown.
+// - StartDate, EndDate: The first and last months of the work on the project in the YYYY-MM format; a null end date means the work is ongoing.
+// - Lifecycle: The development stage of the project (active, maintained or archived), unrelated to Status.
+// - Links: Further typed links (repo, demo, article or video) in display order.
+// - Sections: The detailed write-up of the project as typed sections (problem, solution or results) in display order; only sent with single projects.
+// - Metrics: Key figures of the project as label/value pairs in display order; only sent with single projects.
+// - Status: The publication status (draft, published or archived); new projects are always drafts.
+// - PublishAt: When a draft is published by the scheduler; null for drafts that are published by hand.
+// - Featured: Whether the project is curated for the homepage; lists can be restricted to featured projects.
+// - Position: The manual display order of lists, from 1; new projects without a position are appended.
+// - Locale: The locale of the translated fields of a read; not stored.
+// - Translations: Title and description in the other supported locales; the fields above hold the default locale.
+// - Images: The gallery of uploaded images in display order; only sent with single projects, lists show the cover.
+// - BrokenLinks: The last check of each link found broken by the link checker; only sent to admins, not stored.
 type Project struct {
 	gorm.Model
-	ImageTitle       string `json:"imageTitle"`       // Title of the project's image
-	Image            string `json:"image"`            // URL or path to the project's image
-	ProjectTitle     string `json:"projectTitle"`     // Title of the project
-	ProjectDescription string `json:"projectDescription"` // Description of the project
-	RepositoryLink   string `json:"repositoryLink"`   // Link to the project's repository
+	ImageTitle             string               `json:"imageTitle"`                                                                                            // Title of the project's image
+	Image                  string               `json:"image"`                                                                                                 // URL or path to the project's image
+	ProjectTitle           string               `json:"projectTitle"`                                                                                          // Title of the project
+	Slug                   string               `json:"slug" gorm:"type:varchar(100);uniqueIndex:idx_projects_slug,where:slug <> ''" openapi:"readOnly"`       // Generated from ProjectTitle
+	ProjectDescription     string               `json:"projectDescription" binding:"max=270"`                                                                  // Description of the project in Markdown, at most 270 characters
+	ProjectDescriptionHTML string               `json:"projectDescriptionHtml" gorm:"-" openapi:"readOnly"`                                                    // Description rendered into sanitized HTML
+	ImageWidth             int                  `json:"imageWidth" gorm:"-" openapi:"readOnly"`                                                                // Width of the cover in pixels
+	ImageHeight            int                  `json:"imageHeight" gorm:"-" openapi:"readOnly"`                                                               // Height of the cover in pixels
+	ImageVariants          []ImageVariant       `json:"imageVariants" gorm:"-" openapi:"readOnly"`                                                             // Resized copies of the cover
+	ImageBlurHash          string               `json:"imageBlurHash" gorm:"-" openapi:"readOnly"`                                                             // BlurHash of the cover
+	ImageColor             string               `json:"imageColor" gorm:"-" openapi:"readOnly"`                                                                // Dominant color of the cover as #rrggbb
+	RepositoryLink         string               `json:"repositoryLink"`                                                                                        // Link to the project's repository
+	Repository             RepositoryMetadata   `json:"repository" gorm:"embedded;embeddedPrefix:repository_" openapi:"readOnly"`                              // Synced from the hosting provider
+	DemoURL                string               `json:"demoUrl" binding:"omitempty,url,max=2048"`                                                              // URL of the live demo
+	DocumentationURL       string               `json:"documentationUrl" binding:"omitempty,url,max=2048"`                                                     // URL of the documentation
+	Role                   string               `json:"role" binding:"max=120"`                                                                                // Our role in the project
+	TeamSize               int                  `json:"teamSize" binding:"min=0,max=1000"`                                                                     // Number of people on the project, 0 if unknown
+	StartDate              *string              `json:"startDate" gorm:"type:varchar(7)" binding:"omitempty,datetime=2006-01"`                                 // First month, YYYY-MM
+	EndDate                *string              `json:"endDate" gorm:"type:varchar(7)" binding:"omitempty,datetime=2006-01"`                                   // Last month, YYYY-MM, or null if ongoing
+	Lifecycle              string               `json:"lifecycle" gorm:"type:varchar(16)" binding:"omitempty,oneof=active maintained archived"`                // Development stage
+	Links                  []ProjectLink        `json:"links" gorm:"constraint:OnDelete:CASCADE" binding:"max=20,dive"`                                        // Further links in display order
+	Sections               []ProjectSection     `json:"sections,omitempty" gorm:"constraint:OnDelete:CASCADE" binding:"max=20,dive"`                           // Write-up in display order
+	Metrics                []ProjectMetric      `json:"metrics,omitempty" gorm:"constraint:OnDelete:CASCADE" binding:"max=20,dive"`                            // Key figures in display order
+	Status                 string               `json:"status" gorm:"type:varchar(16);default:draft;index" binding:"omitempty,oneof=draft published archived"` // Publication status
+	PublishAt              *time.Time           `json:"publishAt" gorm:"index"`                                                                                // When the scheduler publishes the draft
+	Featured               bool                 `json:"featured" gorm:"index"`                                                                                 // Whether the project is shown on the homepage
+	Position               int                  `json:"position" gorm:"index" binding:"min=0"`                                                                 // Manual display order, from 1; 0 appends the project
+	Locale                 string               `json:"locale" gorm:"-" openapi:"readOnly"`                                                                    // Locale of the translated fields
+	Translations           []ProjectTranslation `json:"translations" binding:"max=20,dive"`                                                                    // Title and description in the other locales
+	Images                 []ProjectImage       `json:"images,omitempty" gorm:"constraint:OnDelete:CASCADE" openapi:"readOnly"`                                // Gallery in display order
+	BrokenLinks            []ProjectLinkCheck   `json:"brokenLinks,omitempty" gorm:"-" openapi:"readOnly"`                                                     // Links found broken, for admins
+}
+
+// ProjectTranslation holds the translated fields of a project in one locale.
+// An empty field falls back to the next locale of the fallback chain.
+type ProjectTranslation struct {
+	ID                 uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	ProjectID          uint   `json:"-" gorm:"uniqueIndex:idx_project_translations_locale"`
+	Locale             string `json:"locale" gorm:"type:varchar(35);uniqueIndex:idx_project_translations_locale" binding:"required,max=35"` // BCP 47 tag, e.g. id
+	ProjectTitle       string `json:"projectTitle"`                                                                                         // Title of the project
+	ProjectDescription string `json:"projectDescription" binding:"max=270"`                                                                 // Description in Markdown, at most 270 characters
+}
+
+// RepositoryMetadata describes the repository of a project as reported by its Git hosting provider.
+// It is only written by the sync job, and reset when the repository link changes.
+type RepositoryMetadata struct {
+	Stars    int        `json:"stars"`                                    // Number of stars
+	Forks    int        `json:"forks"`                                    // Number of forks
+	Language string     `json:"language"`                                 // Primary language
+	Topics   []string   `json:"topics" gorm:"type:jsonb;serializer:json"` // Topics of the repository
+	PushedAt *time.Time `json:"pushedAt"`                                 // Last push, or last activity on GitLab
+	SyncedAt *time.Time `json:"syncedAt"`                                 // Last sync, null until the first one
+	ETag     string     `json:"-" gorm:"column:etag"`                     // Validator of the last response of the provider
+}
+
+// ProjectLink is a typed link of a project, such as an article or a video about it.
+type ProjectLink struct {
+	ID        uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	ProjectID uint   `json:"-" gorm:"index"`
+	Type      string `json:"type" gorm:"type:varchar(16)" binding:"required,oneof=repo demo article video"` // Kind of the link
+	Title     string `json:"title" binding:"max=120"`                                                       // Text of the link
+	URL       string `json:"url" binding:"required,url,max=2048"`                                           // Target of the link
+	Position  int    `json:"position" openapi:"readOnly"`                                                   // Display order, set from the order of the request
+}
+
+// ProjectSection is a section of the detailed write-up of a project, such as the problem it solves.
+// Several sections may have the same type.
+type ProjectSection struct {
+	ID        uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	ProjectID uint   `json:"-" gorm:"index"`
+	Type      string `json:"type" gorm:"type:varchar(16)" binding:"required,oneof=problem solution results"` // Kind of the section
+	Heading   string `json:"heading" binding:"required,max=160"`                                             // Heading of the section
+	Body      string `json:"body" binding:"max=20000"`                                                       // Content of the section in Markdown
+	BodyHTML  string `json:"bodyHtml" gorm:"-" openapi:"readOnly"`                                           // Body rendered into sanitized HTML
+	Position  int    `json:"position" openapi:"readOnly"`                                                    // Display order, set from the order of the request
+}
+
+// ProjectMetric is a key figure of a project, such as "Active users" and "12k".
+type ProjectMetric struct {
+	ID        uint   `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	ProjectID uint   `json:"-" gorm:"index"`
+	Label     string `json:"label" binding:"required,max=80"` // What the figure measures
+	Value     string `json:"value" binding:"required,max=80"` // The figure, as displayed
+	Position  int    `json:"position" openapi:"readOnly"`     // Display order, set from the order of the request
+}
+
+// ProjectLinkCheck is the result of one check of a link of a project by the link checker.
+// Checks are kept as the status history of each link.
+type ProjectLinkCheck struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProjectID uint      `json:"projectId" gorm:"index:idx_project_link_checks_url"`
+	Field     string    `json:"field" gorm:"type:varchar(64)"`                                   // Field holding the link, e.g. demoUrl or links[0].url
+	URL       string    `json:"url" gorm:"type:varchar(2048);index:idx_project_link_checks_url"` // Checked URL
+	Status    int       `json:"status"`                                                          // HTTP status of the final response, 0 when none was received
+	Error     string    `json:"error"`                                                           // Why no response was received
+	Broken    bool      `json:"broken"`                                                          // Whether the link is considered broken
+	CheckedAt time.Time `json:"checkedAt" gorm:"index"`                                          // When the link was checked
+}
+
+// ProjectSlug is a previous slug of a project. Requests for it are redirected to the current slug,
+// so that shared links keep working after a rename.
+type ProjectSlug struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	ProjectID uint      `json:"-" gorm:"index"`
+	Slug      string    `json:"slug" gorm:"type:varchar(100);uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt"` // When the project stopped using the slug
+}
+
+// ProjectImage is an uploaded image of the gallery of a project.
+// Exactly one image of a non-empty gallery is the cover, which represents the project in list views.
+type ProjectImage struct {
+	ID          uint           `json:"id" gorm:"primaryKey" openapi:"readOnly"`
+	ProjectID   uint           `json:"-" gorm:"index"`
+	Position    int            `json:"position" openapi:"readOnly"`                                   // Display order, from 1
+	Cover       bool           `json:"cover"`                                                         // Whether the image is the cover of the project
+	Alt         string         `json:"alt" binding:"max=255"`                                         // Text alternative of the image
+	Caption     string         `json:"caption" binding:"max=1000"`                                    // Caption shown below the image
+	URL         string         `json:"url" openapi:"readOnly"`                                        // URL of the sanitized image
+	Key         string         `json:"-"`                                                             // Storage key of the image
+	Width       int            `json:"width" openapi:"readOnly"`                                      // Width in pixels
+	Height      int            `json:"height" openapi:"readOnly"`                                     // Height in pixels
+	Variants    []ImageVariant `json:"variants" gorm:"type:jsonb;serializer:json" openapi:"readOnly"` // Resized copies, from the smallest to the largest
+	VariantKeys []string       `json:"-" gorm:"type:jsonb;serializer:json"`                           // Storage keys of Variants
+	BlurHash    string         `json:"blurHash" openapi:"readOnly"`                                   // BlurHash shown while the image loads
+	Color       string         `json:"color" openapi:"readOnly"`                                      // Dominant color as #rrggbb
+	CreatedAt   time.Time      `json:"createdAt" openapi:"readOnly"`
+}
+
+// ProjectStatus is the request body used to change the publication status of a project.
+type ProjectStatus struct {
+	Status    string     `json:"status" binding:"required,oneof=draft published archived"` // New publication status
+	PublishAt *time.Time `json:"publishAt"`                                                // When the scheduler publishes the draft; only allowed for drafts
+}
+
+// ProjectOrder is the request body used to curate the display order of projects.
+// The listed projects come first in the given order, followed by the others in their previous order.
+type ProjectOrder struct {
+	IDs      []uint  `json:"ids" binding:"required,max=1000,unique"`      // IDs of the projects to show first
+	Featured *[]uint `json:"featured" binding:"omitempty,max=100,unique"` // When given, replaces the set of featured projects
+}
+
+// ProjectImageUpdate holds the changes to an image of the gallery; omitted fields are left unchanged.
+// Setting Cover makes the image the cover; the cover cannot be unset, only replaced by another image.
+type ProjectImageUpdate struct {
+	Alt     *string `json:"alt" binding:"omitempty,max=255"`      // Text alternative of the image
+	Caption *string `json:"caption" binding:"omitempty,max=1000"` // Caption shown below the image
+	Cover   *bool   `json:"cover"`                                // true makes the image the cover
+}
+
+// ProjectImageOrder lists every image of a gallery in the new display order.
+type ProjectImageOrder struct {
+	IDs []uint `json:"ids" binding:"required,max=100"` // IDs of the images of the gallery, each exactly once
+}
+
+// ImageVariant is a resized copy of an uploaded image. Opaque images are resized into JPEG and
+// images with transparency into WebP.
+type ImageVariant struct {
+	Name        string `json:"name"`        // thumbnail, medium or large
+	URL         string `json:"url"`         // URL of the copy
+	Width       int    `json:"width"`       // Width in pixels
+	Height      int    `json:"height"`      // Height in pixels
+	ContentType string `json:"contentType"` // Content type of the copy
 }
@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check compares the document with the routes registered on a Gin router and returns an error
+// listing every difference: routes without an operation, operations without a route,
+// duplicate operation IDs and path parameters that are not declared.
+// Routes whose path starts with one of the ignore prefixes, such as the documentation itself, are skipped.
+func (d *Document) Check(routes gin.RoutesInfo, ignore ...string) error {
+	var problems []string
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if hasPrefix(route.Path, ignore) {
+			continue
+		}
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	documented := map[string]bool{}
+	ids := map[string]bool{}
+	for _, op := range d.operations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			problems = append(problems, "duplicate operation "+key)
+		}
+		documented[key] = true
+
+		if op.ID == "" || ids[op.ID] {
+			problems = append(problems, fmt.Sprintf("missing or duplicate operation ID %q for %s", op.ID, key))
+		}
+		ids[op.ID] = true
+
+		for _, name := range pathParams(op.Path) {
+			if !declaresPathParam(op, name) {
+				problems = append(problems, fmt.Sprintf("path parameter %q of %s is not declared", name, key))
+			}
+		}
+
+		if !registered[key] {
+			problems = append(problems, "documented route is not registered: "+key)
+		}
+	}
+
+	for key := range registered {
+		if !documented[key] {
+			problems = append(problems, "registered route is not documented: "+key)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("OpenAPI document does not match the routes:\n- %s", strings.Join(problems, "\n- "))
+}
+
+// pathParams returns the names of the parameters of a Gin path.
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
+
+// declaresPathParam reports whether op declares the path parameter name.
+func declaresPathParam(op Operation, name string) bool {
+	for _, param := range op.Parameters {
+		if param.In == "path" && param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefix reports whether path starts with one of prefixes.
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
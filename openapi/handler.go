@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docsPage is the interactive documentation page. It loads Swagger UI and renders /openapi.json.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Handler serves the document as JSON. The document is encoded once, when the handler is created.
+func Handler(d *Document) gin.HandlerFunc {
+	body, err := json.Marshal(d)
+	if err != nil {
+		// The document only holds plain data, so this is a programming error
+		panic("openapi: encoding document: " + err.Error())
+	}
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
+
+// DocsHandler serves the interactive documentation page.
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
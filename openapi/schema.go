@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // A type name, or a list of names for nullable types
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+// String returns a string schema.
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+// MaxString returns a string schema accepting at most maxLength characters.
+func MaxString(maxLength int) *Schema {
+	return &Schema{Type: "string", MaxLength: &maxLength}
+}
+
+// Integer returns an integer schema with the given minimum.
+func Integer(minimum float64) *Schema {
+	return &Schema{Type: "integer", Minimum: &minimum}
+}
+
+// Boolean returns a boolean schema.
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// Enum returns a string schema that only accepts values.
+func Enum(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	gormModelType = reflect.TypeOf(gorm.Model{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRef returns the schema of values of type t.
+// Named struct types are added to components once and referenced by name.
+func (d *Document) schemaRef(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == deletedAtType:
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer(0)
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaRef(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaRef(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// Register the name first so that recursive types terminate
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of the struct type t.
+// Property names and omission follow encoding/json, the fields of embedded structs are promoted,
+// and the binding tag of gin's validator is translated into required, enum and length keywords.
+// Fields of gorm.Model and fields tagged openapi:"readOnly" are marked read-only.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	d.addFields(schema, t, false)
+	return schema
+}
+
+// addFields adds the fields of the struct type t to schema.
+func (d *Document) addFields(schema *Schema, t reflect.Type, readOnly bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			d.addFields(schema, field.Type, readOnly || field.Type == gormModelType)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaRef(field.Type)
+		required := applyBinding(property, field.Tag.Get("binding"))
+		if field.Type.Kind() == reflect.Pointer {
+			property = nullable(property)
+		}
+		if readOnly || field.Tag.Get("openapi") == "readOnly" {
+			property = withReadOnly(property)
+		}
+		if required && !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// nullable returns a schema that also accepts null.
+func nullable(property *Schema) *Schema {
+	if property.Ref != "" {
+		return &Schema{AnyOf: []*Schema{property, {Type: "null"}}}
+	}
+	property.Type = []interface{}{property.Type, "null"}
+	return property
+}
+
+// withReadOnly marks property as read-only. A reference is wrapped, since keywords next to $ref
+// would change the referenced schema for every user.
+func withReadOnly(property *Schema) *Schema {
+	if property.Ref != "" {
+		return &Schema{AnyOf: []*Schema{property}, ReadOnly: true}
+	}
+	property.ReadOnly = true
+	return property
+}
+
+// applyBinding translates the rules of a binding tag into schema keywords and reports whether
+// the field is required. Rules after "dive" apply to the items of a slice.
+// Rules without a JSON Schema equivalent are ignored.
+//
+// Like the validator, a required string must not be empty, and formats only apply to non-empty strings.
+func applyBinding(schema *Schema, binding string) bool {
+	var required bool
+	rules := strings.Split(binding, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			if schema.Type == "string" && schema.MinLength == nil {
+				one := 1
+				schema.MinLength = &one
+			}
+		case "dive":
+			if schema.Items != nil {
+				applyBinding(schema.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "url":
+			schema.Format = "uri"
+		case "email":
+			schema.Format = "email"
+		case "unique":
+			schema.UniqueItems = schema.Type == "array"
+		case "datetime":
+			switch param {
+			case "2006-01-02":
+				schema.Format = "date"
+			case "2006-01":
+				schema.Pattern = monthPattern
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyLimit(schema, name, n)
+		}
+	}
+	return required
+}
+
+// monthPattern matches a month in the YYYY-MM format.
+const monthPattern = `^[0-9]{4}-(0[1-9]|1[0-2])$`
+
+// applyLimit applies a min, max or len rule of the validator, which limits the length
+// of strings and slices and the value of numbers.
+func applyLimit(schema *Schema, rule string, n int) {
+	value := float64(n)
+	switch schema.Type {
+	case "string":
+		if rule != "max" {
+			schema.MinLength = &n
+		}
+		if rule != "min" {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if rule != "max" {
+			schema.MinItems = &n
+		}
+		if rule != "min" {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		if rule != "max" {
+			schema.Minimum = &value
+		}
+		if rule != "min" {
+			schema.Maximum = &value
+		}
+	}
+}
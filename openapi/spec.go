@@ -0,0 +1,322 @@
+// Package openapi generates the OpenAPI 3.1 description of the API.
+//
+// Routes are described with Operation values next to their registration in the routes package.
+// Request and response schemas are derived from the Go types of the models by reflection,
+// so the document follows changes to the model structs. Check compares the document with the
+// routes registered on the Gin router, so that an undocumented or removed route is noticed
+// before the service starts.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+)
+
+// Version is the OpenAPI version of the generated document.
+const Version = "3.1.0"
+
+// Names of the security schemes that operations can require.
+const (
+	APIKey   = "apiKey"   // x-api-key header, required by every endpoint
+	AdminKey = "adminKey" // x-admin-key header, required by admin endpoints
+)
+
+// Parameter describes a query, path or header parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // query, path or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Query returns an optional query parameter.
+func Query(name string, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// Path returns a path parameter. Path parameters are always required.
+func Path(name string, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+// Header returns an optional header parameter.
+func Header(name string, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: schema}
+}
+
+// FormField describes a field of a multipart/form-data request body.
+type FormField struct {
+	Name        string  // Name of the form field
+	Description string  // Description shown in the documentation
+	Required    bool    // Whether the field must be sent
+	File        bool    // Whether the field is a file upload
+	Schema      *Schema // Schema of a text field, ignored for files
+}
+
+// Operation describes one route of the API.
+type Operation struct {
+	Method      string      // HTTP method, e.g. GET
+	Path        string      // Gin path, e.g. /api/v1/projects/:id
+	ID          string      // Unique operation ID
+	Summary     string      // Short summary shown in the documentation
+	Description string      // Longer description, may use Markdown
+	Tags        []string    // Groups the operation in the documentation
+	Security    []string    // Security schemes required together, e.g. APIKey and AdminKey
+	Parameters  []Parameter // Query, path and header parameters
+	Request     interface{} // Zero value of the JSON request body, nil when there is none
+	Form        []FormField // Fields of a multipart/form-data request body, used instead of Request
+	Status      int         // Status of the success response
+	Response    interface{} // Zero value of the "data" member of the success response, nil when there is none
+	Produces    []string    // Media types of a file response, used instead of the JSON envelope
+	Redirects   []int       // Statuses of redirect responses, sent with a Location header
+	Errors      []int       // Statuses of the problem responses, in addition to the ones implied by Security
+	Deprecated  bool        // Whether the operation is a deprecated alias
+	Successor   string      // Path of the operation replacing a deprecated one
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document is an OpenAPI 3.1 document.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*pathItem `json:"paths"`
+	Components Components                      `json:"components"`
+
+	operations []Operation
+	index      map[string]Operation // Operations by "METHOD path"
+}
+
+// Components holds the reusable parts of the document.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*securityScheme `json:"securitySchemes"`
+}
+
+// securityScheme describes an API key sent in a header.
+type securityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// pathItem is the OpenAPI representation of an operation.
+type pathItem struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+}
+
+// requestBody describes the JSON body of an operation.
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+// response describes one response of an operation.
+type response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*header    `json:"headers,omitempty"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+// header describes a response header.
+type header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// mediaType holds the schema of a body.
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// New builds the document describing operations.
+func New(info Info, operations ...Operation) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]*pathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*securityScheme{
+				APIKey:   {Type: "apiKey", In: "header", Name: "x-api-key", Description: "API key of the client"},
+				AdminKey: {Type: "apiKey", In: "header", Name: "x-admin-key", Description: "Admin key, required in addition to the API key"},
+			},
+		},
+		operations: operations,
+		index:      map[string]Operation{},
+	}
+	problem := d.schemaRef(reflect.TypeOf(apperrors.Problem{}))
+
+	for _, op := range operations {
+		path := openAPIPath(op.Path)
+		if d.Paths[path] == nil {
+			d.Paths[path] = map[string]*pathItem{}
+		}
+		d.Paths[path][strings.ToLower(op.Method)] = d.pathItem(op, problem)
+		d.index[op.Method+" "+op.Path] = op
+	}
+	return d
+}
+
+// Operations returns the operations described by the document.
+func (d *Document) Operations() []Operation {
+	return d.operations
+}
+
+// pathItem converts op into its OpenAPI representation.
+func (d *Document) pathItem(op Operation, problem *Schema) *pathItem {
+	item := &pathItem{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Parameters:  op.Parameters,
+		Responses:   map[string]*response{},
+	}
+
+	if len(op.Security) > 0 {
+		requirement := map[string][]string{}
+		for _, scheme := range op.Security {
+			requirement[scheme] = []string{}
+		}
+		item.Security = []map[string][]string{requirement}
+	}
+
+	if op.Request != nil {
+		item.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]*mediaType{"application/json": {Schema: d.schemaRef(reflect.TypeOf(op.Request))}},
+		}
+	}
+	if len(op.Form) > 0 {
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, field := range op.Form {
+			schema := field.Schema
+			if field.File {
+				schema = &Schema{Type: "string", Format: "binary"}
+			}
+			if field.Description != "" {
+				described := *schema
+				described.Description = field.Description
+				schema = &described
+			}
+			form.Properties[field.Name] = schema
+			if field.Required {
+				form.Required = append(form.Required, field.Name)
+			}
+		}
+		item.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]*mediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+
+	success := &response{Description: http.StatusText(op.Status)}
+	switch {
+	case len(op.Produces) > 0:
+		success.Content = map[string]*mediaType{}
+		for _, contentType := range op.Produces {
+			success.Content[contentType] = &mediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	case op.Response != nil:
+		success.Content = map[string]*mediaType{"application/json": {Schema: d.envelope(op.Response)}}
+	}
+	if op.Deprecated {
+		success.Headers = deprecationHeaders(op.Successor)
+	}
+	item.Responses[strconv.Itoa(op.Status)] = success
+
+	for _, status := range op.Redirects {
+		item.Responses[strconv.Itoa(status)] = &response{
+			Description: http.StatusText(status),
+			Headers:     map[string]*header{"Location": {Description: "URL of the resource", Schema: String()}},
+		}
+	}
+
+	for _, status := range errorStatuses(op) {
+		item.Responses[strconv.Itoa(status)] = &response{
+			Description: http.StatusText(status),
+			Content:     map[string]*mediaType{apperrors.ProblemContentType: {Schema: problem}},
+		}
+	}
+	return item
+}
+
+// envelope returns the schema of the success response {"responseCode": status, "data": data}.
+func (d *Document) envelope(data interface{}) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"responseCode": {Type: "integer", Description: "HTTP status code of the response"},
+			"data":         d.schemaRef(reflect.TypeOf(data)),
+		},
+		Required: []string{"responseCode", "data"},
+	}
+}
+
+// deprecationHeaders describes the headers sent by deprecated aliases.
+func deprecationHeaders(successor string) map[string]*header {
+	return map[string]*header{
+		"Deprecation": {Description: "When the route was deprecated (RFC 9745)", Schema: String()},
+		"Sunset":      {Description: "When the route will be removed (RFC 8594)", Schema: String()},
+		"Link":        {Description: "Successor route: " + successor, Schema: String()},
+	}
+}
+
+// errorStatuses returns the problem statuses of op: the declared ones, the ones implied by its
+// security requirements and parameters, and 500, sorted and without duplicates.
+func errorStatuses(op Operation) []int {
+	statuses := map[int]bool{http.StatusInternalServerError: true}
+	for _, status := range op.Errors {
+		statuses[status] = true
+	}
+	if len(op.Security) > 0 {
+		statuses[http.StatusForbidden] = true
+	}
+	if len(op.Parameters) > 0 {
+		statuses[http.StatusBadRequest] = true
+	}
+	if op.Request != nil || len(op.Form) > 0 {
+		statuses[http.StatusBadRequest] = true
+		statuses[http.StatusUnprocessableEntity] = true
+	}
+
+	sorted := make([]int, 0, len(statuses))
+	for status := range statuses {
+		sorted = append(sorted, status)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// openAPIPath converts a Gin path into an OpenAPI path template, e.g. /projects/:id into /projects/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,419 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrMissingBody is returned by ValidateBody when an operation requires a body and none was sent.
+var ErrMissingBody = errors.New("request body is required")
+
+// direction tells the validator whether a value is sent by the client or by the server.
+// Read-only properties are not required in requests, since the server sets them.
+type direction int
+
+const (
+	inRequest direction = iota
+	inResponse
+)
+
+// Lookup returns the operation registered for method and Gin path, e.g. GET /api/v1/projects/:id.
+func (d *Document) Lookup(method string, path string) (Operation, bool) {
+	op, ok := d.index[method+" "+path]
+	return op, ok
+}
+
+// ValidateParameters validates the path, query and header parameters of a request
+// against the parameters of the operation registered for method and path.
+// Undeclared parameters are ignored. It returns one FieldError per invalid parameter.
+func (d *Document) ValidateParameters(method string, path string, params gin.Params, query url.Values, header http.Header) []apperrors.FieldError {
+	op, ok := d.Lookup(method, path)
+	if !ok {
+		return nil
+	}
+
+	var fields []apperrors.FieldError
+	for _, param := range op.Parameters {
+		var values []string
+		switch param.In {
+		case "path":
+			if value, ok := params.Get(param.Name); ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = header.Values(param.Name)
+		}
+
+		if len(values) == 0 || values[0] == "" {
+			if param.Required {
+				fields = append(fields, apperrors.FieldError{Field: param.Name, Code: "required", Message: "is required"})
+			}
+			continue
+		}
+		fields = append(fields, d.validate(param.Schema, parameterValue(param.Schema, values[0]), param.Name, inRequest)...)
+	}
+	return fields
+}
+
+// ValidateBody validates a JSON request body against the request schema of the operation
+// registered for method and path. It returns ErrMissingBody when a required body is empty,
+// a *json.SyntaxError or similar decoding error when the body is not valid JSON,
+// and otherwise one FieldError per invalid field.
+func (d *Document) ValidateBody(method string, path string, body []byte) ([]apperrors.FieldError, error) {
+	op, ok := d.Lookup(method, path)
+	if !ok || op.Request == nil {
+		return nil, nil
+	}
+	item := d.Paths[openAPIPath(path)][strings.ToLower(method)]
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrMissingBody
+	}
+	value, err := decodeJSON(body)
+	if err != nil {
+		return nil, err
+	}
+	return d.validate(item.RequestBody.Content["application/json"].Schema, value, "", inRequest), nil
+}
+
+// ValidateResponse validates a response body against the schema documented for the status
+// and content type of the operation registered for method and path. Responses without a
+// documented JSON schema are not validated. It returns a description of every violation.
+func (d *Document) ValidateResponse(method string, path string, status int, contentType string, body []byte) []string {
+	item, ok := d.Paths[openAPIPath(path)][strings.ToLower(method)]
+	if !ok {
+		return nil
+	}
+
+	documented, ok := item.Responses[strconv.Itoa(status)]
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	content, ok := documented.Content[strings.TrimSpace(mediaType)]
+	if !ok || content.Schema.Format == "binary" {
+		return nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return []string{"body is not valid JSON: " + err.Error()}
+	}
+
+	var violations []string
+	for _, field := range d.validate(content.Schema, value, "", inResponse) {
+		violations = append(violations, field.Field+": "+field.Message)
+	}
+	return violations
+}
+
+// decodeJSON decodes body, keeping numbers as json.Number so integers can be told apart.
+func decodeJSON(body []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// parameterValue converts a parameter string into the JSON value its schema expects.
+func parameterValue(schema *Schema, value string) interface{} {
+	switch schema.Type {
+	case "integer", "number":
+		return json.Number(value)
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validate validates value against schema and returns one FieldError per violation.
+// field is the path of the value in the document, e.g. links[0].url; it is empty for the root.
+func (d *Document) validate(schema *Schema, value interface{}, field string, dir direction) []apperrors.FieldError {
+	if schema.Ref != "" {
+		return d.validate(d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], value, field, dir)
+	}
+	if len(schema.AnyOf) > 0 {
+		var first []apperrors.FieldError
+		for i, branch := range schema.AnyOf {
+			errs := d.validate(branch, value, field, dir)
+			if len(errs) == 0 {
+				return nil
+			}
+			if i == 0 {
+				first = errs
+			}
+		}
+		return first
+	}
+
+	name := field
+	if name == "" {
+		name = "body"
+	}
+
+	actual := jsonType(value)
+	if !typeMatches(schema.Type, actual, value) {
+		return []apperrors.FieldError{{Field: name, Code: "type", Message: "must be of type " + typeName(schema.Type)}}
+	}
+
+	var fields []apperrors.FieldError
+	fail := func(code string, message string) {
+		fields = append(fields, apperrors.FieldError{Field: name, Code: code, Message: message})
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if length == 0 && schema.MinLength != nil && *schema.MinLength == 1 {
+			// An empty required string is reported as missing, like gin's validator does
+			fail("required", "is required")
+		} else if schema.MinLength != nil && length < *schema.MinLength {
+			fail("min", fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("max", fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, v) {
+			fail("oneof", "must be one of "+strings.Join(schema.Enum, ", "))
+		}
+		if v != "" {
+			if message, ok := checkFormat(schema, v); !ok {
+				fail("format", message)
+			}
+		}
+
+	case json.Number:
+		n, _ := v.Float64()
+		if schema.Minimum != nil && n < *schema.Minimum {
+			fail("min", "must be at least "+formatNumber(*schema.Minimum))
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			fail("max", "must be at most "+formatNumber(*schema.Maximum))
+		}
+
+	case []interface{}:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			fail("min", fmt.Sprintf("must contain at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			fail("max", fmt.Sprintf("must contain at most %d items", *schema.MaxItems))
+		}
+		if schema.UniqueItems && !uniqueItems(v) {
+			fail("unique", "must not contain repeated items")
+		}
+		if schema.Items != nil {
+			for i, item := range v {
+				fields = append(fields, d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), dir)...)
+			}
+		}
+
+	case map[string]interface{}:
+		for _, required := range schema.Required {
+			if dir == inRequest && isReadOnly(schema.Properties[required]) {
+				continue
+			}
+			if _, ok := v[required]; !ok {
+				fields = append(fields, apperrors.FieldError{Field: join(field, required), Code: "required", Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			item := v[key]
+			property, ok := schema.Properties[key]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property == nil {
+				continue
+			}
+			fields = append(fields, d.validate(property, item, join(field, key), dir)...)
+		}
+	}
+	return fields
+}
+
+// patterns caches the compiled pattern keywords of the document.
+var patterns sync.Map
+
+// checkFormat checks a non-empty string against the format and pattern keywords of schema.
+// It returns a message describing the expected format when the value does not match.
+func checkFormat(schema *Schema, value string) (string, bool) {
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 timestamp", false
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date in the YYYY-MM-DD format", false
+		}
+	case "uri":
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL", false
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "must be a valid email address", false
+		}
+	}
+
+	if schema.Pattern != "" {
+		compiled, ok := patterns.Load(schema.Pattern)
+		if !ok {
+			compiled, _ = patterns.LoadOrStore(schema.Pattern, regexp.MustCompile(schema.Pattern))
+		}
+		if !compiled.(*regexp.Regexp).MatchString(value) {
+			if schema.Pattern == monthPattern {
+				return "must be a month in the YYYY-MM format", false
+			}
+			return "must match the pattern " + schema.Pattern, false
+		}
+	}
+	return "", true
+}
+
+// isReadOnly reports whether the property schema is read-only.
+func isReadOnly(schema *Schema) bool {
+	return schema != nil && schema.ReadOnly
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		if _, err := v.Float64(); err == nil {
+			return "number"
+		}
+		return "string"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// uniqueItems reports whether the items of a JSON array are distinct.
+func uniqueItems(items []interface{}) bool {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		// Encoding decoded JSON sorts object keys, so equal values encode equally
+		key, err := json.Marshal(item)
+		if err != nil || seen[string(key)] {
+			return false
+		}
+		seen[string(key)] = true
+	}
+	return true
+}
+
+// typeMatches reports whether a value of the JSON type actual satisfies the type keyword expected,
+// which is empty, a type name or a list of type names.
+func typeMatches(expected interface{}, actual string, value interface{}) bool {
+	switch t := expected.(type) {
+	case nil:
+		return true
+	case string:
+		if t == "number" && actual == "integer" {
+			return true
+		}
+		if t == "integer" && actual == "number" {
+			// 1.0 is an integer in JSON Schema
+			n, _ := value.(json.Number).Float64()
+			return n == math.Trunc(n)
+		}
+		return t == actual
+	case []string:
+		for _, name := range t {
+			if typeMatches(name, actual, value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, name := range t {
+			if typeMatches(name, actual, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns a readable form of a type keyword, e.g. "string or null".
+func typeName(t interface{}) string {
+	switch t := t.(type) {
+	case []string:
+		return strings.Join(t, " or ")
+	case []interface{}:
+		names := make([]string, len(t))
+		for i, name := range t {
+			names[i] = fmt.Sprint(name)
+		}
+		return strings.Join(names, " or ")
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// join appends a property name to a field path.
+func join(field string, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// contains reports whether values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatNumber formats a schema limit without a needless fraction.
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
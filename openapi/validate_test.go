@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// testItem is the request and response body of the test document.
+type testItem struct {
+	ID    uint    `json:"id" binding:"required" openapi:"readOnly"`
+	Title string  `json:"title" binding:"required,max=10"`
+	Count int     `json:"count" binding:"min=1"`
+	Score float64 `json:"score"`
+	Note  *string `json:"note"`
+}
+
+// traceLength is the maximum length of the x-trace header of the test document.
+var traceLength = 4
+
+// newTestDocument returns a document with an operation creating testItem values and one reading them.
+func newTestDocument() *Document {
+	return New(Info{Title: "Test", Version: "1"},
+		Operation{Method: http.MethodPost, Path: "/items", ID: "createItem", Request: testItem{}, Status: http.StatusCreated, Response: testItem{}},
+		Operation{
+			Method: http.MethodGet, Path: "/items/:id", ID: "getItem", Status: http.StatusOK, Response: testItem{},
+			Parameters: []Parameter{
+				Path("id", "ID of the item", Integer(1)),
+				Query("drafts", "Include drafts", &Schema{Type: "boolean"}),
+				Query("limit", "Maximum number of items", Integer(1)),
+				{Name: "x-trace", In: "header", Description: "Trace ID", Schema: &Schema{Type: "string", MaxLength: &traceLength}},
+			},
+		},
+	)
+}
+
+// fieldCodes returns the fields as "field:code" strings.
+func fieldCodes(fields []apperrors.FieldError) string {
+	codes := make([]string, len(fields))
+	for i, field := range fields {
+		codes[i] = field.Field + ":" + field.Code
+	}
+	return strings.Join(codes, " ")
+}
+
+func TestValidateBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string // Fields with their codes, empty when the body is valid
+	}{
+		{"valid", `{"title":"Portfolio","count":2,"score":1.5,"note":"hi"}`, ""},
+		{"read-only id is not required", `{"title":"Portfolio"}`, ""},
+		{"missing field", `{"count":2}`, "title:required"},
+		{"empty required string", `{"title":""}`, "title:required"},
+		{"too long", `{"title":"A long portfolio title"}`, "title:max"},
+		{"integer written as a fraction", `{"title":"a","count":1.0}`, ""},
+		{"fraction for an integer", `{"title":"a","count":1.5}`, "count:type"},
+		{"below the minimum", `{"title":"a","count":0}`, "count:min"},
+		{"integer for a number", `{"title":"a","score":2}`, ""},
+		{"string for a number", `{"title":"a","score":"2"}`, "score:type"},
+		{"null for a nullable field", `{"title":"a","note":null}`, ""},
+		{"wrong type for a nullable field", `{"title":"a","note":5}`, "note:type"},
+		{"null for a required field", `{"title":null}`, "title:type"},
+		{"array body", `[]`, "body:type"},
+	}
+	doc := newTestDocument()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := doc.ValidateBody(http.MethodPost, "/items", []byte(tt.body))
+			if err != nil {
+				t.Fatalf("ValidateBody() returned error: %v", err)
+			}
+			if got := fieldCodes(fields); got != tt.want {
+				t.Errorf("ValidateBody(%s) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBodyErrors(t *testing.T) {
+	doc := newTestDocument()
+	tests := []struct {
+		name    string
+		body    string
+		missing bool // Whether the error is ErrMissingBody
+	}{
+		{"empty body", "", true},
+		{"blank body", " \n", true},
+		{"invalid JSON", `{"title":`, false},
+		{"trailing JSON", `{"title":"a"} {"title":"b"}`, false},
+		{"trailing garbage", `{"title":"a"}x`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := doc.ValidateBody(http.MethodPost, "/items", []byte(tt.body))
+			if err == nil || errors.Is(err, ErrMissingBody) != tt.missing {
+				t.Errorf("ValidateBody(%q) = %v, want an error (missing body: %t)", tt.body, err, tt.missing)
+			}
+		})
+	}
+
+	// Operations without a request body accept anything
+	if fields, err := doc.ValidateBody(http.MethodGet, "/items/:id", nil); fields != nil || err != nil {
+		t.Errorf("ValidateBody() of an operation without body = %v, %v, want nothing", fields, err)
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		header string
+		want   string
+	}{
+		{"valid", "7", "drafts=true&limit=10", "abc", ""},
+		{"no optional parameters", "7", "", "", ""},
+		{"empty optional parameter", "7", "limit=", "", ""},
+		{"missing path parameter", "", "", "", "id:required"},
+		{"not an integer", "x", "", "", "id:type"},
+		{"zero id", "0", "", "", "id:min"},
+		{"fraction", "7", "limit=1.5", "", "limit:type"},
+		{"not a boolean", "7", "drafts=yes", "", "drafts:type"},
+		{"first value is checked", "7", "limit=2&limit=x", "", ""},
+		{"header too long", "7", "", "abcdef", "x-trace:max"},
+		{"several errors", "x", "limit=0", "", "id:type limit:min"},
+	}
+	doc := newTestDocument()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params gin.Params
+			if tt.id != "" {
+				params = gin.Params{{Key: "id", Value: tt.id}}
+			}
+			query, _ := url.ParseQuery(tt.query)
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("X-Trace", tt.header)
+			}
+
+			fields := doc.ValidateParameters(http.MethodGet, "/items/:id", params, query, header)
+			if got := fieldCodes(fields); got != tt.want {
+				t.Errorf("ValidateParameters() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        string // Expected violations, joined with "; "
+	}{
+		{"valid", http.StatusCreated, "application/json; charset=utf-8", `{"responseCode":201,"data":{"id":1,"title":"a","note":null}}`, ""},
+		{"read-only id is required", http.StatusCreated, "application/json", `{"responseCode":201,"data":{"title":"a"}}`, "data.id: is required"},
+		{"missing envelope", http.StatusCreated, "application/json", `{"id":1,"title":"a"}`, "responseCode: is required; data: is required"},
+		{"undocumented status", http.StatusTeapot, "application/json", `{}`, "status 418 is not documented"},
+		{"problem", http.StatusInternalServerError, apperrors.ProblemContentType, `{"type":"about:blank","title":"Internal Server Error","status":500}`, ""},
+		{"problem with a wrong type", http.StatusInternalServerError, apperrors.ProblemContentType, `{"type":"about:blank","title":"Internal Server Error","status":"500"}`, "status: must be of type integer"},
+		{"undocumented content type", http.StatusCreated, "text/plain", `not JSON`, ""},
+		{"invalid JSON", http.StatusCreated, "application/json", `{"responseCode":`, "body is not valid JSON: unexpected EOF"},
+	}
+	doc := newTestDocument()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := doc.ValidateResponse(http.MethodPost, "/items", tt.status, tt.contentType, []byte(tt.body))
+			if got := strings.Join(violations, "; "); got != tt.want {
+				t.Errorf("ValidateResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeMatches(t *testing.T) {
+	tests := []struct {
+		expected interface{}
+		value    interface{}
+		want     bool
+	}{
+		{nil, "anything", true},
+		{"string", "a", true},
+		{"string", nil, false},
+		{"integer", jsonNumber("1"), true},
+		{"integer", jsonNumber("1.0"), true},
+		{"integer", jsonNumber("1e3"), true},
+		{"integer", jsonNumber("1.5"), false},
+		{"number", jsonNumber("1"), true},
+		{"number", jsonNumber("1.5"), true},
+		{"boolean", true, true},
+		{"boolean", "true", false},
+		{[]string{"string", "null"}, nil, true},
+		{[]string{"string", "null"}, "a", true},
+		{[]string{"string", "null"}, false, false},
+		{[]interface{}{"integer", "null"}, jsonNumber("2.0"), true},
+		{[]interface{}{"integer", "null"}, jsonNumber("2.5"), false},
+	}
+	for _, tt := range tests {
+		if got := typeMatches(tt.expected, jsonType(tt.value), tt.value); got != tt.want {
+			t.Errorf("typeMatches(%v, %#v) = %t, want %t", tt.expected, tt.value, got, tt.want)
+		}
+	}
+}
+
+// jsonNumber decodes a JSON number the way the validator does.
+func jsonNumber(s string) interface{} {
+	value, err := decodeJSON([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func TestValidateBodyUniqueItems(t *testing.T) {
+	type tagged struct {
+		Tags []string          `json:"tags" binding:"unique"`
+		Sets [][]interface{}   `json:"sets" binding:"unique"`
+		Maps []json.RawMessage `json:"maps" binding:"unique"`
+	}
+	doc := New(Info{Title: "Test", Version: "1"}, Operation{Method: http.MethodPut, Path: "/tags", ID: "putTags", Request: tagged{}, Status: http.StatusOK})
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"tags":["a","b"]}`, ""},
+		{`{"tags":["a","a"]}`, "tags:unique"},
+		{`{"sets":[[1,2],[2,1]]}`, ""},
+		{`{"sets":[[1,2],[1,2]]}`, "sets:unique"},
+		{`{"maps":[{"a":1,"b":2},{"b":2,"a":1}]}`, "maps:unique"},
+	}
+	for _, tt := range tests {
+		fields, err := doc.ValidateBody(http.MethodPut, "/tags", []byte(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fieldCodes(fields); got != tt.want {
+			t.Errorf("ValidateBody(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package aboutrepositories implements the persistence of the "About" document and its revisions.
+package aboutrepositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores and loads the "About" document together with its collections and revisions.
+// The current document is the most recent entry; older entries are only kept for FindByID.
+// Documents are loaded with their translations, which are saved and replaced like the collections.
+// Errors are translated with repositories.Translate, so callers can test them with errors.Is.
+type Repository interface {
+	// Current returns the current document, or repositories.ErrNotFound when none was saved yet.
+	Current(ctx context.Context) (aboutmodels.About, error)
+	// FindByID returns the entry with the given ID, or repositories.ErrNotFound.
+	FindByID(ctx context.Context, id uint) (aboutmodels.About, error)
+	// Save replaces the current document with about, including its collections, and records it as
+	// a new revision saved by author, in a single transaction. rolledBackFrom is the number of the
+	// revision restored by a rollback, or nil. The saved document is reloaded into about.
+	// It returns repositories.ErrConflict when another save recorded the same revision number.
+	Save(ctx context.Context, about *aboutmodels.About, author string, rolledBackFrom *int) (aboutmodels.AboutRevision, error)
+	// Revisions returns every revision without its snapshot, the most recent first.
+	Revisions(ctx context.Context) ([]aboutmodels.AboutRevision, error)
+	// Revision returns the revision with the given number, or repositories.ErrNotFound.
+	Revision(ctx context.Context, number int) (aboutmodels.AboutRevision, error)
+}
+
+// collections are the associations of About, loaded and replaced with the entry.
+var collections = []string{"SocialLinks", "Skills", "Experience", "Education"}
+
+// repository is the GORM implementation of Repository.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a Repository backed by db.
+// It panics when db is nil, so that a missing dependency fails at startup.
+func NewRepository(db *gorm.DB) Repository {
+	if db == nil {
+		panic("aboutrepositories: nil database")
+	}
+	return &repository{db: db}
+}
+
+// preload loads the collections of the entries found by tx in their saved order,
+// and their translations ordered by locale.
+func preload(tx *gorm.DB) *gorm.DB {
+	for _, association := range collections {
+		tx = tx.Preload(association, func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		})
+	}
+	return tx.Preload("Translations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("locale")
+	})
+}
+
+func (r *repository) Current(ctx context.Context) (aboutmodels.About, error) {
+	var about aboutmodels.About
+	err := preload(repositories.Session(ctx, r.db)).Order("id desc").First(&about).Error
+	return about, repositories.Translate(err)
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (aboutmodels.About, error) {
+	var about aboutmodels.About
+	err := preload(repositories.Session(ctx, r.db)).First(&about, id).Error
+	return about, repositories.Translate(err)
+}
+
+func (r *repository) Save(ctx context.Context, about *aboutmodels.About, author string, rolledBackFrom *int) (aboutmodels.AboutRevision, error) {
+	var revision aboutmodels.AboutRevision
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Locking the current document serializes concurrent saves
+		var current aboutmodels.About
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "created_at").Order("id desc").First(&current).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Generated fields are never taken from the client
+			about.Model = gorm.Model{}
+		case err != nil:
+			return err
+		default:
+			about.Model = gorm.Model{ID: current.ID, CreatedAt: current.CreatedAt}
+
+			// The collections are replaced as a whole, so the old rows are removed first
+			for _, model := range []interface{}{
+				&aboutmodels.SocialLink{}, &aboutmodels.Skill{}, &aboutmodels.Experience{}, &aboutmodels.Education{},
+				&aboutmodels.AboutTranslation{},
+			} {
+				if err := tx.Where("about_id = ?", about.ID).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+		}
+		clearIDs(about)
+
+		var latest int
+		if err := tx.Model(&aboutmodels.AboutRevision{}).Select("COALESCE(MAX(number), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		about.Revision = latest + 1
+
+		if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(about).Error; err != nil {
+			return err
+		}
+
+		var saved aboutmodels.About
+		if err := preload(tx).First(&saved, about.ID).Error; err != nil {
+			return err
+		}
+		*about = saved
+
+		revision = aboutmodels.AboutRevision{
+			Number:         saved.Revision,
+			Author:         author,
+			RolledBackFrom: rolledBackFrom,
+			Snapshot:       &saved,
+		}
+		return tx.Create(&revision).Error
+	})
+	return revision, repositories.Translate(err)
+}
+
+func (r *repository) Revisions(ctx context.Context) ([]aboutmodels.AboutRevision, error) {
+	var revisions []aboutmodels.AboutRevision
+	err := repositories.Session(ctx, r.db).Omit("Snapshot").Order("number desc").Find(&revisions).Error
+	return revisions, repositories.Translate(err)
+}
+
+func (r *repository) Revision(ctx context.Context, number int) (aboutmodels.AboutRevision, error) {
+	var revision aboutmodels.AboutRevision
+	err := repositories.Session(ctx, r.db).Where("number = ?", number).First(&revision).Error
+	return revision, repositories.Translate(err)
+}
+
+// clearIDs resets the IDs of the collection entries and translations, so that they are inserted as new rows.
+func clearIDs(about *aboutmodels.About) {
+	for i := range about.SocialLinks {
+		about.SocialLinks[i].ID = 0
+	}
+	for i := range about.Skills {
+		about.Skills[i].ID = 0
+	}
+	for i := range about.Experience {
+		about.Experience[i].ID = 0
+	}
+	for i := range about.Education {
+		about.Education[i].ID = 0
+	}
+	for i := range about.Translations {
+		about.Translations[i].ID = 0
+	}
+}
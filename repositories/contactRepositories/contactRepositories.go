@@ -0,0 +1,100 @@
+// Package contactrepositories implements the persistence of "Contact" entries.
+package contactrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Filter restricts the contact messages returned by FindInBatches.
+// Zero values do not restrict the result.
+type Filter struct {
+	From   time.Time // Only messages created at or after this time
+	To     time.Time // Only messages created before this time
+	Status string    // Only messages with this status
+}
+
+// Repository stores and loads "Contact" entries.
+// Errors are translated with repositories.Translate, so callers can test them with errors.Is.
+type Repository interface {
+	// Create inserts a new entry and fills its generated fields.
+	// The insert runs in its own transaction, so a nil error means that the entry is committed.
+	// It returns repositories.ErrConflict when the entry violates a unique constraint.
+	Create(ctx context.Context, contact *contactmodels.Contact) error
+	// FindByID returns the entry with the given ID, or repositories.ErrNotFound.
+	FindByID(ctx context.Context, id uint) (contactmodels.Contact, error)
+	// FindAll returns every entry.
+	FindAll(ctx context.Context) ([]contactmodels.Contact, error)
+	// UpdateStatus changes the status of an entry.
+	UpdateStatus(ctx context.Context, contact *contactmodels.Contact, status string) error
+	// FindInBatches loads the entries matching filter in batches of batchSize rows, ordered by ID,
+	// and calls fn for every batch. It stops at the first error returned by fn.
+	FindInBatches(ctx context.Context, filter Filter, batchSize int, fn func(batch []contactmodels.Contact) error) error
+}
+
+// repository is the GORM implementation of Repository.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a Repository backed by db.
+// It panics when db is nil, so that a missing dependency fails at startup.
+func NewRepository(db *gorm.DB) Repository {
+	if db == nil {
+		panic("contactrepositories: nil database")
+	}
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, contact *contactmodels.Contact) error {
+	return repositories.Translate(repositories.Session(ctx, r.db).Create(contact).Error)
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (contactmodels.Contact, error) {
+	var contact contactmodels.Contact
+	err := repositories.Session(ctx, r.db).First(&contact, id).Error
+	return contact, repositories.Translate(err)
+}
+
+func (r *repository) FindAll(ctx context.Context) ([]contactmodels.Contact, error) {
+	var contacts []contactmodels.Contact
+	err := repositories.Session(ctx, r.db).Find(&contacts).Error
+	return contacts, repositories.Translate(err)
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, contact *contactmodels.Contact, status string) error {
+	changes := map[string]interface{}{"status": status}
+	// The retention policy counts the age of archived messages from when they were archived
+	if status != contact.Status {
+		if status == contactmodels.StatusArchived {
+			changes["archived_at"] = time.Now()
+		} else {
+			changes["archived_at"] = nil
+		}
+	}
+	return repositories.Translate(repositories.Session(ctx, r.db).Model(contact).Updates(changes).Error)
+}
+
+func (r *repository) FindInBatches(ctx context.Context, filter Filter, batchSize int, fn func(batch []contactmodels.Contact) error) error {
+	query := repositories.Session(ctx, r.db).Model(&contactmodels.Contact{})
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var batch []contactmodels.Contact
+	result := query.Order("id").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	return repositories.Translate(result.Error)
+}
@@ -0,0 +1,72 @@
+// Package repositories contains what the persistence layer of every entity shares:
+// the errors returned by repositories and the translation of database driver errors into them.
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Errors returned by repositories. They wrap the original driver error, so use errors.Is to test for them.
+var (
+	ErrNotFound    = errors.New("record not found")
+	ErrConflict    = errors.New("record conflicts with an existing record")
+	ErrUnavailable = errors.New("database unavailable")
+)
+
+// Session returns the database session used by repositories for a request.
+// Statements are prepared and cached, and the request context is attached for tracing and cancellation.
+func Session(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(ctx).Session(&gorm.Session{PrepareStmt: true})
+}
+
+// Translate wraps a database error in the matching repository error.
+// Errors that do not match any repository error are returned unchanged.
+func Translate(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case isUniqueViolation(err):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case isConnectionError(err):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique or exclusion constraint violation.
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "23505" || pgErr.Code == "23P01")
+}
+
+// isConnectionError reports whether err means that the database cannot be reached,
+// so that the request may succeed when retried later.
+func isConnectionError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection exception; 53300 is too_many_connections; 57P0x is shutdown
+		return strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "53300" ||
+			pgErr.Code == "57P01" || pgErr.Code == "57P02" || pgErr.Code == "57P03"
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		pgconn.Timeout(err)
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"not found", gorm.ErrRecordNotFound, ErrNotFound},
+		{"duplicated key", gorm.ErrDuplicatedKey, ErrConflict},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, ErrConflict},
+		{"exclusion violation", fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23P01"}), ErrConflict},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, ErrUnavailable},
+		{"too many connections", &pgconn.PgError{Code: "53300"}, ErrUnavailable},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, ErrUnavailable},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrUnavailable},
+		{"bad connection", driver.ErrBadConn, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Translate(tt.err)
+			if !errors.Is(got, tt.want) || !errors.Is(got, tt.err) {
+				t.Errorf("Translate(%v) = %v, want it to wrap %v and the original error", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateUnknownErrors(t *testing.T) {
+	if Translate(nil) != nil {
+		t.Error("Translate(nil) != nil")
+	}
+	for _, err := range []error{
+		&pgconn.PgError{Code: "42P01"}, // undefined_table
+		context.Canceled,
+		errors.New("syntax error"),
+	} {
+		if got := Translate(err); got != err {
+			t.Errorf("Translate(%v) = %v, want the error unchanged", err, got)
+		}
+	}
+}
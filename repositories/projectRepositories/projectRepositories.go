@@ -0,0 +1,597 @@
+// Package projectrepositories implements the persistence of "Project" entries.
+package projectrepositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/slugs"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrImageOrder is returned by ReorderImages when the IDs are not the images of the gallery, each exactly once.
+var ErrImageOrder = errors.New("image order must list every image of the gallery exactly once")
+
+// ErrProjectOrder is returned by Reorder when it lists an entry that does not exist.
+var ErrProjectOrder = errors.New("project order lists an unknown project")
+
+// Filter selects the entries returned by FindAll. The zero value selects the entries visible to the public.
+type Filter struct {
+	Drafts   bool // Also return draft and archived entries
+	Featured bool // Only return featured entries
+}
+
+// Repository stores and loads "Project" entries together with their translations and gallery.
+// Errors are translated with repositories.Translate, so callers can test them with errors.Is.
+type Repository interface {
+	// Create inserts a new entry with its translations and fills their generated fields, including a unique slug.
+	// An entry without a position is appended to the display order.
+	// It returns repositories.ErrConflict when the entry violates a unique constraint.
+	Create(ctx context.Context, project *projectmodels.Project) error
+	// Update replaces the content, the translations, the links, the write-up and the metrics of the entry with project.ID, leaving its status,
+	// display order and gallery unchanged, or returns repositories.ErrNotFound. When the title changes,
+	// the entry gets a new slug and the previous one is kept in its slug history, and when the repository link
+	// changes, the synced metadata of the repository is reset.
+	Update(ctx context.Context, project *projectmodels.Project) error
+	// FindByID returns the entry with the given ID with its write-up, metrics and whole gallery, or repositories.ErrNotFound.
+	FindByID(ctx context.Context, id uint) (projectmodels.Project, error)
+	// FindBySlug returns the ID and the current slug of the entry whose current or previous slug is slug,
+	// or repositories.ErrNotFound.
+	FindBySlug(ctx context.Context, slug string) (uint, string, error)
+	// BackfillSlugs generates the slug of the entries created before slugs existed and returns their IDs.
+	BackfillSlugs(ctx context.Context) ([]uint, error)
+	// FindAll returns the entries selected by filter in display order, with only the cover of their gallery
+	// and without their write-up and metrics.
+	FindAll(ctx context.Context, filter Filter) ([]projectmodels.Project, error)
+	// UpdateStatus sets the publication status and publication time of the entry with the given ID,
+	// or returns repositories.ErrNotFound.
+	UpdateStatus(ctx context.Context, id uint, status projectmodels.ProjectStatus) error
+	// Reorder moves the entries listed in order to the front of the display order and renumbers every entry,
+	// and replaces the featured entries when order.Featured is set, in one transaction.
+	// It returns ErrProjectOrder when order lists an entry that does not exist.
+	Reorder(ctx context.Context, order projectmodels.ProjectOrder) error
+	// FindRepositories returns every entry with a repository link, drafts included, without translations or gallery.
+	FindRepositories(ctx context.Context) ([]projectmodels.Project, error)
+	// UpdateRepository stores the synced metadata of the repository of the entry with the given ID.
+	// Nothing is stored when the repository link of the entry is no longer link, since the metadata describes another repository.
+	UpdateRepository(ctx context.Context, id uint, link string, metadata projectmodels.RepositoryMetadata) error
+	// RecordLinkChecks stores the results of a run of the link checker and removes the checks made before the given time.
+	RecordLinkChecks(ctx context.Context, checks []projectmodels.ProjectLinkCheck, before time.Time) error
+	// LatestLinkChecks returns the last check of every link of the entries with the given IDs, or of every entry when ids is nil.
+	LatestLinkChecks(ctx context.Context, ids []uint) ([]projectmodels.ProjectLinkCheck, error)
+	// LinkChecks returns the checks of the links of the entry with the given ID, the most recent first,
+	// or repositories.ErrNotFound when the entry does not exist.
+	LinkChecks(ctx context.Context, id uint) ([]projectmodels.ProjectLinkCheck, error)
+	// PublishDue publishes the drafts whose publication time is not after now and returns their IDs.
+	PublishDue(ctx context.Context, now time.Time) ([]uint, error)
+	// AddImage appends image to the gallery of the entry with image.ProjectID and fills its generated fields.
+	// The first image of a gallery always becomes the cover. It returns repositories.ErrNotFound when the entry does not exist.
+	AddImage(ctx context.Context, image *projectmodels.ProjectImage) error
+	// UpdateImage applies update to the image with imageID of the entry with the given ID,
+	// or returns repositories.ErrNotFound.
+	UpdateImage(ctx context.Context, id uint, imageID uint, update projectmodels.ProjectImageUpdate) error
+	// ReorderImages sets the display order of the gallery of the entry with the given ID to the order of ids.
+	// It returns ErrImageOrder when ids are not the images of the gallery, each exactly once.
+	ReorderImages(ctx context.Context, id uint, ids []uint) error
+	// DeleteImage removes the image with imageID from the gallery of the entry with the given ID and returns it.
+	// When the cover is removed, the first remaining image becomes the cover.
+	DeleteImage(ctx context.Context, id uint, imageID uint) (projectmodels.ProjectImage, error)
+}
+
+// repository is the GORM implementation of Repository.
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a Repository backed by db.
+// It panics when db is nil, so that a missing dependency fails at startup.
+func NewRepository(db *gorm.DB) Repository {
+	if db == nil {
+		panic("projectrepositories: nil database")
+	}
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, project *projectmodels.Project) error {
+	// Translations, links, sections and metrics are always inserted as new rows, and images are only added with AddImage
+	for i := range project.Translations {
+		project.Translations[i].ID = 0
+	}
+	numberRows(project)
+	project.Images = nil
+
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if project.Position == 0 {
+			var last int
+			if err := tx.Model(&projectmodels.Project{}).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+				return err
+			}
+			project.Position = last + 1
+		}
+		slug, err := uniqueSlug(tx, project.ProjectTitle, 0)
+		if err != nil {
+			return err
+		}
+		project.Slug = slug
+		return tx.Create(project).Error
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) Update(ctx context.Context, project *projectmodels.Project) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var current projectmodels.Project
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "project_title", "slug", "repository_link").First(&current, project.ID).Error
+		if err != nil {
+			return err
+		}
+
+		project.Slug = current.Slug
+		if current.Slug == "" || current.ProjectTitle != project.ProjectTitle {
+			if project.Slug, err = uniqueSlug(tx, project.ProjectTitle, project.ID); err != nil {
+				return err
+			}
+		}
+		if project.Slug != current.Slug {
+			if err := moveSlug(tx, project.ID, current.Slug, project.Slug); err != nil {
+				return err
+			}
+		}
+
+		if current.RepositoryLink != project.RepositoryLink {
+			err := tx.Model(&projectmodels.Project{}).Where("id = ?", project.ID).Select(repositoryColumns).
+				Updates(&projectmodels.Project{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&projectmodels.Project{}).Where("id = ?", project.ID).Updates(map[string]interface{}{
+			"image_title":         project.ImageTitle,
+			"image":               project.Image,
+			"project_title":       project.ProjectTitle,
+			"slug":                project.Slug,
+			"project_description": project.ProjectDescription,
+			"repository_link":     project.RepositoryLink,
+			"demo_url":            project.DemoURL,
+			"documentation_url":   project.DocumentationURL,
+			"role":                project.Role,
+			"team_size":           project.TeamSize,
+			"start_date":          project.StartDate,
+			"end_date":            project.EndDate,
+			"lifecycle":           project.Lifecycle,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Translations, links, sections and metrics are replaced as a whole, like they are sent
+		models := []interface{}{
+			&projectmodels.ProjectTranslation{}, &projectmodels.ProjectLink{}, &projectmodels.ProjectSection{}, &projectmodels.ProjectMetric{},
+		}
+		for _, model := range models {
+			if err := tx.Where("project_id = ?", project.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		for i := range project.Translations {
+			project.Translations[i].ID, project.Translations[i].ProjectID = 0, project.ID
+		}
+		numberRows(project)
+		inserts := []struct {
+			rows  interface{}
+			count int
+		}{
+			{&project.Translations, len(project.Translations)},
+			{&project.Links, len(project.Links)},
+			{&project.Sections, len(project.Sections)},
+			{&project.Metrics, len(project.Metrics)},
+		}
+		for _, insert := range inserts {
+			if insert.count == 0 {
+				continue
+			}
+			if err := tx.Create(insert.rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (projectmodels.Project, error) {
+	var project projectmodels.Project
+	err := preload(repositories.Session(ctx, r.db)).First(&project, id).Error
+	return project, repositories.Translate(err)
+}
+
+func (r *repository) FindBySlug(ctx context.Context, slug string) (uint, string, error) {
+	db := repositories.Session(ctx, r.db)
+	var project projectmodels.Project
+	err := db.Select("id", "slug").Where("slug = ?", slug).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Model(&projectmodels.ProjectSlug{}).
+			Select("projects.id", "projects.slug").
+			Joins("JOIN projects ON projects.id = project_slugs.project_id AND projects.deleted_at IS NULL").
+			Where("project_slugs.slug = ?", slug).
+			Take(&project).Error
+	}
+	return project.ID, project.Slug, repositories.Translate(err)
+}
+
+func (r *repository) BackfillSlugs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var projects []projectmodels.Project
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "project_title").
+			Where("slug = '' OR slug IS NULL").Order("id").Find(&projects).Error
+		if err != nil {
+			return err
+		}
+		for _, project := range projects {
+			slug, err := uniqueSlug(tx, project.ProjectTitle, project.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&projectmodels.Project{}).Where("id = ?", project.ID).Update("slug", slug).Error; err != nil {
+				return err
+			}
+			ids = append(ids, project.ID)
+		}
+		return nil
+	})
+	return ids, repositories.Translate(err)
+}
+
+func (r *repository) FindAll(ctx context.Context, filter Filter) ([]projectmodels.Project, error) {
+	tx := preloadContent(repositories.Session(ctx, r.db)).Preload("Images", "cover")
+	if !filter.Drafts {
+		tx = tx.Where("status = ?", projectmodels.StatusPublished)
+	}
+	if filter.Featured {
+		tx = tx.Where("featured")
+	}
+
+	var projects []projectmodels.Project
+	err := tx.Order("position, id").Find(&projects).Error
+	return projects, repositories.Translate(err)
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, id uint, status projectmodels.ProjectStatus) error {
+	result := repositories.Session(ctx, r.db).Model(&projectmodels.Project{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status.Status, "publish_at": status.PublishAt})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return repositories.Translate(gorm.ErrRecordNotFound)
+	}
+	return repositories.Translate(result.Error)
+}
+
+func (r *repository) Reorder(ctx context.Context, order projectmodels.ProjectOrder) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Locking every entry serializes concurrent reorders
+		var existing []uint
+		err := tx.Model(&projectmodels.Project{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Order("position, id").Pluck("id", &existing).Error
+		if err != nil {
+			return err
+		}
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+
+		listed := make(map[uint]bool, len(order.IDs))
+		for _, id := range order.IDs {
+			if !known[id] {
+				return fmt.Errorf("%w: %d", ErrProjectOrder, id)
+			}
+			listed[id] = true
+		}
+		ids := append([]uint{}, order.IDs...)
+		for _, id := range existing {
+			if !listed[id] {
+				ids = append(ids, id)
+			}
+		}
+		for i, id := range ids {
+			if err := tx.Model(&projectmodels.Project{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		if order.Featured == nil {
+			return nil
+		}
+		for _, id := range *order.Featured {
+			if !known[id] {
+				return fmt.Errorf("%w: %d", ErrProjectOrder, id)
+			}
+		}
+		if err := tx.Model(&projectmodels.Project{}).Where("featured").Update("featured", false).Error; err != nil {
+			return err
+		}
+		if len(*order.Featured) == 0 {
+			return nil
+		}
+		return tx.Model(&projectmodels.Project{}).Where("id IN ?", *order.Featured).Update("featured", true).Error
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) FindRepositories(ctx context.Context) ([]projectmodels.Project, error) {
+	var projects []projectmodels.Project
+	err := repositories.Session(ctx, r.db).Where("repository_link <> ''").Order("id").Find(&projects).Error
+	return projects, repositories.Translate(err)
+}
+
+func (r *repository) UpdateRepository(ctx context.Context, id uint, link string, metadata projectmodels.RepositoryMetadata) error {
+	// Only the selected columns are written, so that a sync does not change updatedAt
+	err := repositories.Session(ctx, r.db).Model(&projectmodels.Project{}).
+		Where("id = ? AND repository_link = ?", id, link).Select(repositoryColumns).
+		Updates(&projectmodels.Project{Repository: metadata}).Error
+	return repositories.Translate(err)
+}
+
+func (r *repository) RecordLinkChecks(ctx context.Context, checks []projectmodels.ProjectLinkCheck, before time.Time) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if len(checks) > 0 {
+			if err := tx.CreateInBatches(checks, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("checked_at < ?", before).Delete(&projectmodels.ProjectLinkCheck{}).Error
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) LatestLinkChecks(ctx context.Context, ids []uint) ([]projectmodels.ProjectLinkCheck, error) {
+	db := repositories.Session(ctx, r.db)
+	latest := db.Model(&projectmodels.ProjectLinkCheck{}).Select("MAX(id)").Group("project_id, url")
+	if ids != nil {
+		latest = latest.Where("project_id IN ?", ids)
+	}
+
+	var checks []projectmodels.ProjectLinkCheck
+	err := db.Where("id IN (?)", latest).Order("project_id, id").Find(&checks).Error
+	return checks, repositories.Translate(err)
+}
+
+func (r *repository) LinkChecks(ctx context.Context, id uint) ([]projectmodels.ProjectLinkCheck, error) {
+	db := repositories.Session(ctx, r.db)
+	var project projectmodels.Project
+	if err := db.Select("id").First(&project, id).Error; err != nil {
+		return nil, repositories.Translate(err)
+	}
+
+	var checks []projectmodels.ProjectLinkCheck
+	err := db.Where("project_id = ?", id).Order("checked_at desc, id desc").Find(&checks).Error
+	return checks, repositories.Translate(err)
+}
+
+func (r *repository) PublishDue(ctx context.Context, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&projectmodels.Project{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND publish_at <= ?", projectmodels.StatusDraft, now).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return tx.Model(&projectmodels.Project{}).Where("id IN ?", ids).Update("status", projectmodels.StatusPublished).Error
+	})
+	return ids, repositories.Translate(err)
+}
+
+func (r *repository) AddImage(ctx context.Context, image *projectmodels.ProjectImage) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, image.ProjectID); err != nil {
+			return err
+		}
+
+		var gallery []projectmodels.ProjectImage
+		if err := tx.Select("id", "cover", "position").Where("project_id = ?", image.ProjectID).Find(&gallery).Error; err != nil {
+			return err
+		}
+		image.ID, image.Position = 0, 1
+		for _, existing := range gallery {
+			image.Position = max(image.Position, existing.Position+1)
+		}
+		if len(gallery) == 0 {
+			image.Cover = true
+		} else if image.Cover {
+			if err := clearCover(tx, image.ProjectID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(image).Error
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) UpdateImage(ctx context.Context, id uint, imageID uint, update projectmodels.ProjectImageUpdate) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, id); err != nil {
+			return err
+		}
+
+		var image projectmodels.ProjectImage
+		if err := tx.Where("project_id = ?", id).First(&image, imageID).Error; err != nil {
+			return err
+		}
+
+		fields := map[string]interface{}{}
+		if update.Alt != nil {
+			fields["alt"] = *update.Alt
+		}
+		if update.Caption != nil {
+			fields["caption"] = *update.Caption
+		}
+		// The cover is only replaced, never unset, so that a gallery always has one
+		if update.Cover != nil && *update.Cover && !image.Cover {
+			if err := clearCover(tx, id); err != nil {
+				return err
+			}
+			fields["cover"] = true
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		return tx.Model(&image).Updates(fields).Error
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) ReorderImages(ctx context.Context, id uint, ids []uint) error {
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, id); err != nil {
+			return err
+		}
+
+		var existing []uint
+		if err := tx.Model(&projectmodels.ProjectImage{}).Where("project_id = ?", id).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		remaining := make(map[uint]bool, len(existing))
+		for _, imageID := range existing {
+			remaining[imageID] = true
+		}
+		for _, imageID := range ids {
+			if !remaining[imageID] {
+				return ErrImageOrder
+			}
+			delete(remaining, imageID)
+		}
+		if len(remaining) > 0 {
+			return ErrImageOrder
+		}
+
+		for i, imageID := range ids {
+			if err := tx.Model(&projectmodels.ProjectImage{}).Where("id = ?", imageID).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return repositories.Translate(err)
+}
+
+func (r *repository) DeleteImage(ctx context.Context, id uint, imageID uint) (projectmodels.ProjectImage, error) {
+	var image projectmodels.ProjectImage
+	err := repositories.Session(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, id); err != nil {
+			return err
+		}
+
+		if err := tx.Where("project_id = ?", id).First(&image, imageID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		if !image.Cover {
+			return nil
+		}
+
+		var next projectmodels.ProjectImage
+		err := tx.Select("id").Where("project_id = ?", id).Order("position, id").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("cover", true).Error
+	})
+	return image, repositories.Translate(err)
+}
+
+// lockProject locks the entry with the given ID for the rest of the transaction tx, which serializes
+// concurrent changes to its gallery, or returns gorm.ErrRecordNotFound.
+func lockProject(tx *gorm.DB, id uint) error {
+	var project projectmodels.Project
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&project, id).Error
+}
+
+// repositoryColumns are the columns of projectmodels.RepositoryMetadata.
+var repositoryColumns = []string{
+	"repository_stars", "repository_forks", "repository_language", "repository_topics",
+	"repository_pushed_at", "repository_synced_at", "repository_etag",
+}
+
+// uniqueSlug returns the slug of title for the entry with the given ID, 0 for a new entry, suffixed when
+// it is taken by another entry, deleted ones included, or is a previous slug of another entry.
+// A previous slug of the entry itself is free, so that reverting a rename restores its slug.
+func uniqueSlug(tx *gorm.DB, title string, id uint) (string, error) {
+	base := slugs.Make(title, "project")
+	for n := 1; ; n++ {
+		candidate := slugs.WithSuffix(base, n)
+		var taken int64
+		err := tx.Unscoped().Model(&projectmodels.Project{}).Where("slug = ? AND id <> ?", candidate, id).Count(&taken).Error
+		if err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			err = tx.Model(&projectmodels.ProjectSlug{}).Where("slug = ? AND project_id <> ?", candidate, id).Count(&taken).Error
+			if err != nil {
+				return "", err
+			}
+		}
+		if taken == 0 {
+			return candidate, nil
+		}
+	}
+}
+
+// moveSlug records that the entry with the given ID changes its slug from previous to slug.
+// The previous slug is kept in the history, and slug leaves it when the entry used it before.
+func moveSlug(tx *gorm.DB, id uint, previous string, slug string) error {
+	if err := tx.Where("project_id = ? AND slug = ?", id, slug).Delete(&projectmodels.ProjectSlug{}).Error; err != nil {
+		return err
+	}
+	if previous == "" {
+		return nil
+	}
+	return tx.Create(&projectmodels.ProjectSlug{ProjectID: id, Slug: previous}).Error
+}
+
+// clearCover unsets the cover of the gallery of the entry with the given ID.
+func clearCover(tx *gorm.DB, id uint) error {
+	return tx.Model(&projectmodels.ProjectImage{}).Where("project_id = ? AND cover", id).Update("cover", false).Error
+}
+
+// preload loads the translations and links of the entries found by tx, and their gallery, write-up
+// and metrics in display order. Lists use preloadContent, since they only show the summary of entries.
+func preload(tx *gorm.DB) *gorm.DB {
+	inOrder := func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}
+	return preloadContent(tx).Preload("Sections", inOrder).Preload("Metrics", inOrder).Preload("Images", inOrder)
+}
+
+// preloadContent loads the translations of the entries found by tx, ordered by locale, and their links in display order.
+func preloadContent(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Translations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("locale")
+	}).Preload("Links", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	})
+}
+
+// numberRows prepares the links, sections and metrics of project to be inserted as new rows, in the order they are sent.
+func numberRows(project *projectmodels.Project) {
+	for i := range project.Links {
+		project.Links[i].ID, project.Links[i].ProjectID, project.Links[i].Position = 0, project.ID, i+1
+	}
+	for i := range project.Sections {
+		project.Sections[i].ID, project.Sections[i].ProjectID, project.Sections[i].Position = 0, project.ID, i+1
+	}
+	for i := range project.Metrics {
+		project.Metrics[i].ID, project.Metrics[i].ProjectID, project.Metrics[i].Position = 0, project.ID, i+1
+	}
+}
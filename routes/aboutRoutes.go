@@ -1,24 +1,178 @@
-
 package routes
 
 import (
+	"net/http"
+
 	"github.com/EkoAgustina/go-ms-portfolio/controllers/aboutControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
 	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
+	"github.com/EkoAgustina/go-ms-portfolio/openapi"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupAboutRoutes configures routes for "about" endpoints on the Gin router.
+// The "about" entity is a single document; every save is recorded as a revision.
 // This function sets up the following routes:
-// - POST /createAbout: Creates a new "about" entity. Validated with ValidateApiKey middleware.
-// - GET /about: Retrieves an "about" entity by ID. Validated with ValidateApiKey middleware.
+// - GET /api/v1/about: Retrieves the current "about" document. Validated with ValidateApiKey middleware.
+// - PUT /api/v1/about: Replaces the "about" document as a new revision. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/about/revisions: Lists the revisions. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/about/revisions/diff: Compares two revisions. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/about/revisions/:revision: Retrieves a revision. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - POST /api/v1/about/revisions/:revision/rollback: Restores a revision. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/about/translations/missing: Reports the missing translations. Validated with ValidateApiKey and ValidateAdminKey middleware.
+//
+// Reads return the translated content in the locale chosen from the "lang" query parameter or the Accept-Language header.
+//
+// The following legacy routes are kept as deprecated aliases and send Deprecation and Sunset headers:
+// - POST /createAbout: Alias of PUT /api/v1/about, responding with 201 Created.
+// - GET /about: Alias of GET /api/v1/about, responding with a list of one entry; also accepts an "id" query parameter.
+//
+// Every route validates its parameters and body against the OpenAPI document with ValidateRequest middleware.
 //
 // Parameters:
 // - router: The Gin router instance to configure.
+// - handler: The handler serving the endpoints.
 //
 // Example:
 //   router := gin.Default()
-//   routes.SetupAboutRoutes(router)
-func SetupAboutRoutes(router *gin.Engine) {
-	router.POST("/createAbout", middlewares.ValidateApiKey(), aboutcontrollers.CreateAbout)
-	router.GET("/about", middlewares.ValidateApiKey(), aboutcontrollers.GetAbout)
+//   routes.SetupAboutRoutes(router, aboutcontrollers.NewHandler(service, locales))
+func SetupAboutRoutes(router *gin.Engine, handler *aboutcontrollers.Handler) {
+	v1 := router.Group(APIPrefix, middlewares.ValidateApiKey())
+	v1.GET("/about", validateRequest(), handler.GetCurrentAbout)
+	v1.PUT("/about", middlewares.ValidateAdminKey(), validateRequest(), handler.SaveAbout)
+
+	revisions := v1.Group("/about/revisions", middlewares.ValidateAdminKey())
+	revisions.GET("", validateRequest(), handler.ListRevisions)
+	revisions.GET("/diff", validateRequest(), handler.DiffRevisions)
+	revisions.GET("/:revision", validateRequest(), handler.GetRevision)
+	revisions.POST("/:revision/rollback", validateRequest(), handler.RollbackRevision)
+	v1.GET("/about/translations/missing", middlewares.ValidateAdminKey(), validateRequest(), handler.GetMissingTranslations)
+
+	router.POST("/createAbout", deprecated(APIPrefix+"/about"), middlewares.ValidateApiKey(), validateRequest(), handler.CreateAbout)
+	router.GET("/about", deprecated(APIPrefix+"/about"), middlewares.ValidateApiKey(), validateRequest(), handler.GetAbout)
+}
+
+// authorParameter is the header naming the author of the revision saved by a request.
+var authorParameter = openapi.Header("X-Author", "Author recorded in the saved revision; anonymous when absent", openapi.MaxString(100))
+
+// revisionParameter is the path parameter identifying a revision.
+var revisionParameter = openapi.Path("revision", "Number of the revision", openapi.Integer(1))
+
+// aboutOperations describes the "about" routes in the OpenAPI document.
+func aboutOperations() []openapi.Operation {
+	get := openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     APIPrefix + "/about",
+		ID:         "getAbout",
+		Summary:    "Get the about document",
+		Tags:       []string{"About"},
+		Security:   []string{openapi.APIKey},
+		Parameters: localeParameters,
+		Status:     http.StatusOK,
+		Response:   aboutmodels.About{},
+		Errors:     readErrors,
+	}
+	save := openapi.Operation{
+		Method:      http.MethodPut,
+		Path:        APIPrefix + "/about",
+		ID:          "saveAbout",
+		Summary:     "Replace the about document",
+		Description: "Replaces the profile and records it as a new revision; the collections sent replace the stored ones, in the order they are sent.",
+		Tags:        []string{"About"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters:  []openapi.Parameter{authorParameter},
+		Request:     aboutmodels.About{},
+		Status:      http.StatusOK,
+		Response:    aboutmodels.About{},
+		Errors:      writeErrors,
+	}
+
+	listRevisions := openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        APIPrefix + "/about/revisions",
+		ID:          "listAboutRevisions",
+		Summary:     "List the revisions of the about document",
+		Description: "Lists the revisions without their snapshots, the most recent first.",
+		Tags:        []string{"About"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Status:      http.StatusOK,
+		Response:    []aboutmodels.AboutRevision{},
+		Errors:      []int{http.StatusServiceUnavailable},
+	}
+	from := openapi.Query("from", "Number of the older revision", openapi.Integer(1))
+	from.Required = true
+	to := openapi.Query("to", "Number of the newer revision", openapi.Integer(1))
+	to.Required = true
+	diffRevisions := openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        APIPrefix + "/about/revisions/diff",
+		ID:          "diffAboutRevisions",
+		Summary:     "Compare two revisions of the about document",
+		Description: "Lists the fields added, removed or changed from one revision to the other. Collection entries are compared by position.",
+		Tags:        []string{"About"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters:  []openapi.Parameter{from, to},
+		Status:      http.StatusOK,
+		Response:    []aboutmodels.Change{},
+		Errors:      readErrors,
+	}
+	getRevision := openapi.Operation{
+		Method:     http.MethodGet,
+		Path:       APIPrefix + "/about/revisions/:revision",
+		ID:         "getAboutRevision",
+		Summary:    "Get a revision of the about document",
+		Tags:       []string{"About"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{revisionParameter},
+		Status:     http.StatusOK,
+		Response:   aboutmodels.AboutRevision{},
+		Errors:     readErrors,
+	}
+	rollback := openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        APIPrefix + "/about/revisions/:revision/rollback",
+		ID:          "rollbackAboutRevision",
+		Summary:     "Roll back the about document",
+		Description: "Restores the document of a revision and records it as a new revision.",
+		Tags:        []string{"About"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters:  []openapi.Parameter{revisionParameter, authorParameter},
+		Status:      http.StatusCreated,
+		Response:    aboutmodels.AboutRevision{},
+		Errors:      append([]int{http.StatusNotFound}, writeErrors...),
+	}
+
+	missingTranslations := openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        APIPrefix + "/about/translations/missing",
+		ID:          "listMissingAboutTranslations",
+		Summary:     "List missing about translations",
+		Description: "Lists, for every translated locale, the fields of the about document that are not translated.",
+		Tags:        []string{"About"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Status:      http.StatusOK,
+		Response:    []i18n.MissingTranslation{},
+		Errors:      []int{http.StatusServiceUnavailable},
+	}
+
+	legacyCreate := legacyOperation(save, "/createAbout", "legacyCreateAbout")
+	legacyCreate.Method = http.MethodPost
+	legacyCreate.Status = http.StatusCreated
+	legacyCreate.Security = []string{openapi.APIKey}
+	legacyList := legacyOperation(get, "/about", "legacyListAbout")
+	legacyList.Parameters = append([]openapi.Parameter{idQueryParameter}, localeParameters...)
+	legacyList.Response = []aboutmodels.About{}
+
+	return []openapi.Operation{
+		get,
+		save,
+		listRevisions,
+		diffRevisions,
+		getRevision,
+		rollback,
+		missingTranslations,
+		legacyCreate,
+		legacyList,
+	}
 }
@@ -2,23 +2,168 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/EkoAgustina/go-ms-portfolio/controllers/contactControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/jobs"
 	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/openapi"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupContactRoutes configures routes for "contact" endpoints on the Gin router.
 // This function sets up the following routes:
-// - POST /contactme: Creates a new contact entry. Validated with ValidateApiKey middleware.
-// - GET /contactme: Retrieves contact entries. Validated with ValidateApiKey middleware.
+// - POST /api/v1/contacts: Creates a new contact entry. Validated with ValidateApiKey middleware.
+// - GET /api/v1/contacts: Retrieves contact entries. Validated with ValidateApiKey middleware.
+// - GET /api/v1/contacts/:id: Retrieves a contact entry by ID. Validated with ValidateApiKey middleware.
+// - PATCH /api/v1/contacts/:id: Changes the status of a contact entry. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/contacts/export: Exports contact entries as a file. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/contacts/retention: Retrieves the retention job metrics. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - POST /api/v1/contacts/retention/dry-run: Previews the retention policy. Validated with ValidateApiKey and ValidateAdminKey middleware.
+//
+// The following legacy routes are kept as deprecated aliases and send Deprecation and Sunset headers:
+// - POST /contactme: Alias of POST /api/v1/contacts.
+// - GET /contactme: Alias of GET /api/v1/contacts; also accepts an "id" query parameter.
+// - GET /contactme/export: Alias of GET /api/v1/contacts/export.
+// - PATCH /contactme/:id: Alias of PATCH /api/v1/contacts/:id.
+// - GET /contactme/retention: Alias of GET /api/v1/contacts/retention.
+// - POST /contactme/retention/dry-run: Alias of POST /api/v1/contacts/retention/dry-run.
+//
+// Every route validates its parameters and body against the OpenAPI document with ValidateRequest middleware.
 //
 // Parameters:
 // - router: The Gin router instance to configure.
+// - handler: The handler serving the endpoints.
 //
 // Example:
 //   router := gin.Default()
-//   routes.SetupContactRoutes(router)
-func SetupContactRoutes(router *gin.Engine) {
-	router.POST("/contactme", middlewares.ValidateApiKey(), contactcontrollers.CreateContact)
-	router.GET("/contactme", middlewares.ValidateApiKey(), contactcontrollers.GetContactMe)
+//   routes.SetupContactRoutes(router, contactcontrollers.NewHandler(service, retentionJob))
+func SetupContactRoutes(router *gin.Engine, handler *contactcontrollers.Handler) {
+	v1 := router.Group(APIPrefix+"/contacts", middlewares.ValidateApiKey())
+	v1.POST("", validateRequest(), handler.CreateContact)
+	v1.GET("", validateRequest(), handler.GetContactMe)
+	v1.GET("/:id", validateRequest(), handler.GetContactByID)
+	v1.PATCH("/:id", middlewares.ValidateAdminKey(), validateRequest(), handler.UpdateContactStatus)
+	v1.GET("/export", middlewares.ValidateAdminKey(), validateRequest(), handler.ExportContacts)
+	v1.GET("/retention", middlewares.ValidateAdminKey(), validateRequest(), handler.GetRetentionReport)
+	v1.POST("/retention/dry-run", middlewares.ValidateAdminKey(), validateRequest(), handler.DryRunRetention)
+
+	legacy := router.Group("/contactme")
+	legacy.POST("", deprecated(APIPrefix+"/contacts"), middlewares.ValidateApiKey(), validateRequest(), handler.CreateContact)
+	legacy.GET("", deprecated(APIPrefix+"/contacts"), middlewares.ValidateApiKey(), validateRequest(), handler.GetContactMe)
+	legacy.GET("/export", deprecated(APIPrefix+"/contacts/export"), middlewares.ValidateApiKey(), middlewares.ValidateAdminKey(), validateRequest(), handler.ExportContacts)
+	legacy.PATCH("/:id", deprecated(APIPrefix+"/contacts/:id"), middlewares.ValidateApiKey(), middlewares.ValidateAdminKey(), validateRequest(), handler.UpdateContactStatus)
+	legacy.GET("/retention", deprecated(APIPrefix+"/contacts/retention"), middlewares.ValidateApiKey(), middlewares.ValidateAdminKey(), validateRequest(), handler.GetRetentionReport)
+	legacy.POST("/retention/dry-run", deprecated(APIPrefix+"/contacts/retention/dry-run"), middlewares.ValidateApiKey(), middlewares.ValidateAdminKey(), validateRequest(), handler.DryRunRetention)
+}
+
+// contactOperations describes the "contact" routes in the OpenAPI document.
+func contactOperations() []openapi.Operation {
+	create := openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        APIPrefix + "/contacts",
+		ID:          "createContact",
+		Summary:     "Send a contact message",
+		Description: "Stores the message with the status new and notifies the site owner by email.",
+		Tags:        []string{"Contacts"},
+		Security:    []string{openapi.APIKey},
+		Request:     contactmodels.Contact{},
+		Status:      http.StatusCreated,
+		Response:    contactmodels.Contact{},
+		Errors:      writeErrors,
+	}
+	list := openapi.Operation{
+		Method:     http.MethodGet,
+		Path:       APIPrefix + "/contacts",
+		ID:         "listContacts",
+		Summary:    "List contact messages",
+		Tags:       []string{"Contacts"},
+		Security:   []string{openapi.APIKey},
+		Parameters: []openapi.Parameter{idQueryParameter},
+		Status:     http.StatusOK,
+		Response:   []contactmodels.Contact{},
+		Errors:     readErrors,
+	}
+	get := openapi.Operation{
+		Method:     http.MethodGet,
+		Path:       APIPrefix + "/contacts/:id",
+		ID:         "getContact",
+		Summary:    "Get a contact message",
+		Tags:       []string{"Contacts"},
+		Security:   []string{openapi.APIKey},
+		Parameters: []openapi.Parameter{idParameter},
+		Status:     http.StatusOK,
+		Response:   contactmodels.Contact{},
+		Errors:     readErrors,
+	}
+	updateStatus := openapi.Operation{
+		Method:     http.MethodPatch,
+		Path:       APIPrefix + "/contacts/:id",
+		ID:         "updateContactStatus",
+		Summary:    "Change the status of a contact message",
+		Tags:       []string{"Contacts"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter},
+		Request:    contactmodels.ContactStatus{},
+		Status:     http.StatusOK,
+		Response:   contactmodels.Contact{},
+		Errors:     readErrors,
+	}
+	export := openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        APIPrefix + "/contacts/export",
+		ID:          "exportContacts",
+		Summary:     "Export contact messages",
+		Description: "Streams the matching messages as a file attachment.",
+		Tags:        []string{"Contacts"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{
+			openapi.Query("format", "Export format (default csv)", openapi.Enum("csv", "jsonl", "mbox")),
+			openapi.Query("from", "Only messages created on or after this date (YYYY-MM-DD or RFC 3339)", openapi.String()),
+			openapi.Query("to", "Only messages created before the end of this date (YYYY-MM-DD or RFC 3339)", openapi.String()),
+			openapi.Query("status", "Only messages with this status", openapi.Enum(contactmodels.StatusNew, contactmodels.StatusSpam, contactmodels.StatusArchived)),
+		},
+		Status:   http.StatusOK,
+		Produces: []string{"text/csv", "application/x-ndjson", "application/mbox"},
+		Errors:   []int{http.StatusServiceUnavailable},
+	}
+	retention := openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     APIPrefix + "/contacts/retention",
+		ID:       "getRetentionReport",
+		Summary:  "Get the retention job metrics",
+		Tags:     []string{"Contacts"},
+		Security: []string{openapi.APIKey, openapi.AdminKey},
+		Status:   http.StatusOK,
+		Response: jobs.RetentionSummary{},
+	}
+	dryRun := openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        APIPrefix + "/contacts/retention/dry-run",
+		ID:          "dryRunRetention",
+		Summary:     "Preview the retention policy",
+		Description: "Counts the rows every retention rule would touch without changing the database.",
+		Tags:        []string{"Contacts"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Status:      http.StatusOK,
+		Response:    jobs.RetentionReport{},
+		Errors:      []int{http.StatusServiceUnavailable},
+	}
+
+	return []openapi.Operation{
+		create,
+		list,
+		get,
+		updateStatus,
+		export,
+		retention,
+		dryRun,
+		legacyOperation(create, "/contactme", "legacyCreateContact"),
+		legacyOperation(list, "/contactme", "legacyListContacts"),
+		legacyOperation(export, "/contactme/export", "legacyExportContacts"),
+		legacyOperation(updateStatus, "/contactme/:id", "legacyUpdateContactStatus"),
+		legacyOperation(retention, "/contactme/retention", "legacyGetRetentionReport"),
+		legacyOperation(dryRun, "/contactme/retention/dry-run", "legacyDryRunRetention"),
+	}
 }
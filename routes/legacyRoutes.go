@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"log"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix is the path prefix of the current API version.
+const APIPrefix = "/api/v1"
+
+// legacyDateLayout is the layout of the legacy route dates in the environment.
+const legacyDateLayout = "2006-01-02"
+
+// deprecated returns the middleware marking a legacy route as a deprecated alias of successor.
+//
+// Environment Variables:
+// - LEGACY_ROUTES_DEPRECATED_AT: Date the legacy routes were deprecated, as YYYY-MM-DD (default 2026-10-19).
+// - LEGACY_ROUTES_SUNSET_AT: Date the legacy routes will be removed, as YYYY-MM-DD (default 2027-04-19).
+func deprecated(successor string) gin.HandlerFunc {
+	deprecatedAt := loadLegacyDate("LEGACY_ROUTES_DEPRECATED_AT", "2026-10-19")
+	sunsetAt := loadLegacyDate("LEGACY_ROUTES_SUNSET_AT", "2027-04-19")
+	return middlewares.Deprecated(deprecatedAt, sunsetAt, successor)
+}
+
+// loadLegacyDate parses a legacy route date from the environment.
+// It exits the application when the date is malformed, so the mistake is noticed at startup.
+func loadLegacyDate(key string, fallback string) time.Time {
+	value := utils.LoadEnvDefault(key, fallback)
+	date, err := time.Parse(legacyDateLayout, value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: expected YYYY-MM-DD", key, value)
+	}
+	return date
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"log"
+
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMetricsRoutes configures the Prometheus metrics endpoint on the Gin router.
+// This function sets up the following route:
+// - GET /metrics: Serves the service metrics. Validated with ValidateMetricsToken middleware.
+//
+// The endpoint is only registered when the METRICS_TOKEN environment variable is set.
+//
+// Parameters:
+// - router: The Gin router instance to configure.
+//
+// Example:
+//
+//	router := gin.Default()
+//	routes.SetupMetricsRoutes(router)
+func SetupMetricsRoutes(router *gin.Engine) {
+	token := utils.LoadEnvDefault("METRICS_TOKEN", "")
+	if token == "" {
+		log.Println("METRICS_TOKEN not set, metrics endpoint disabled")
+		return
+	}
+
+	router.GET("/metrics", middlewares.ValidateMetricsToken(token), gin.WrapH(metrics.Handler()))
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// undocumentedPaths are the route prefixes left out of the OpenAPI document:
+// the documentation itself, the Prometheus endpoint and the uploaded files.
+var undocumentedPaths = []string{"/openapi.json", "/docs", "/metrics", UploadsPath}
+
+// spec is the OpenAPI document, built once and shared by the documentation and the validation.
+var spec = sync.OnceValue(buildSpec)
+
+// Spec returns the OpenAPI document describing the routes configured by this package.
+func Spec() *openapi.Document {
+	return spec()
+}
+
+// validateRequest returns the middleware validating requests against the OpenAPI document.
+// It is added to every route right before the handler, so that unauthenticated requests are
+// rejected by the key middleware first.
+func validateRequest() gin.HandlerFunc {
+	return middlewares.ValidateRequest(spec())
+}
+
+// buildSpec builds the OpenAPI document from the operations of every route file.
+func buildSpec() *openapi.Document {
+	var operations []openapi.Operation
+	operations = append(operations, aboutOperations()...)
+	operations = append(operations, projectOperations()...)
+	operations = append(operations, contactOperations()...)
+
+	return openapi.New(openapi.Info{
+		Title:       "Portfolio API",
+		Version:     "1.0.0",
+		Description: "API of the portfolio website. Errors are returned as RFC 7807 problem documents.",
+	}, operations...)
+}
+
+// CheckSpec compares the OpenAPI document with the routes registered on router.
+// It returns an error listing the routes that are not documented and the documented routes that do not exist.
+func CheckSpec(router *gin.Engine) error {
+	return Spec().Check(router.Routes(), undocumentedPaths...)
+}
+
+// SetupOpenAPIRoutes configures the API documentation routes on the Gin router.
+// This function sets up the following routes:
+// - GET /openapi.json: Serves the OpenAPI 3.1 document of the API.
+// - GET /docs: Serves the interactive documentation page.
+//
+// The documentation is public, so these routes are not validated with ValidateApiKey middleware.
+//
+// Parameters:
+// - router: The Gin router instance to configure.
+//
+// Example:
+//
+//	router := gin.Default()
+//	routes.SetupOpenAPIRoutes(router)
+func SetupOpenAPIRoutes(router *gin.Engine) {
+	router.GET("/openapi.json", openapi.Handler(Spec()))
+	router.GET("/docs", openapi.DocsHandler)
+}
+
+// legacyOperation describes a deprecated alias of op served at path with the operation ID id.
+func legacyOperation(op openapi.Operation, path string, id string) openapi.Operation {
+	legacy := op
+	legacy.Path = path
+	legacy.ID = id
+	legacy.Deprecated = true
+	legacy.Successor = op.Path
+	legacy.Description = "Deprecated alias of " + op.Method + " " + op.Path + "."
+	return legacy
+}
+
+// idParameter is the path parameter identifying an entry.
+var idParameter = openapi.Path("id", "ID of the entry", openapi.Integer(1))
+
+// idQueryParameter is the optional query parameter of the list endpoints that selects a single entry.
+var idQueryParameter = openapi.Query("id", "Only return the entry with this ID, as a list of one entry", openapi.Integer(1))
+
+// localeParameters select the locale of translated content: the "lang" query parameter
+// takes precedence over the Accept-Language header.
+var localeParameters = []openapi.Parameter{
+	openapi.Query("lang", "Locale of the translated content, e.g. id; unsupported locales fall back to the configured chain",
+		&openapi.Schema{Type: "string", Pattern: `^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`}),
+	openapi.Header("Accept-Language", "Preferred locales of the translated content, used when lang is absent", openapi.String()),
+}
+
+// readErrors are the problem statuses of endpoints reading entries.
+var readErrors = []int{http.StatusNotFound, http.StatusServiceUnavailable}
+
+// writeErrors are the problem statuses of endpoints creating entries.
+var writeErrors = []int{http.StatusConflict, http.StatusServiceUnavailable}
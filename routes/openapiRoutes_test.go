@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter registers every documented route on a router that is never served, like cmd/openapi.
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	t.Setenv("ENV_FILE", os.DevNull)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	SetupAboutRoutes(router, nil)
+	SetupProjectRoutes(router, nil)
+	SetupContactRoutes(router, nil)
+	SetupOpenAPIRoutes(router)
+	return router
+}
+
+func TestCheckSpec(t *testing.T) {
+	if err := CheckSpec(newTestRouter(t)); err != nil {
+		t.Fatalf("routes and OpenAPI document disagree: %v", err)
+	}
+}
+
+func TestCheckSpecReportsUndocumentedRoutes(t *testing.T) {
+	router := newTestRouter(t)
+	router.GET(APIPrefix+"/undocumented", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	err := CheckSpec(router)
+	if err == nil || !strings.Contains(err.Error(), APIPrefix+"/undocumented") {
+		t.Fatalf("CheckSpec() = %v, want an error naming the undocumented route", err)
+	}
+}
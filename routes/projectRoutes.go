@@ -1,23 +1,283 @@
 package routes
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/EkoAgustina/go-ms-portfolio/controllers/projectControllers"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
 	"github.com/EkoAgustina/go-ms-portfolio/middlewares"
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/openapi"
+	"github.com/EkoAgustina/go-ms-portfolio/slugs"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupProjectRoutes configures routes for "project" endpoints on the Gin router.
 // This function sets up the following routes:
-// - POST /addProject: Creates a new "project" entity. Validated with ValidateApiKey middleware.
-// - GET /project: Retrieves a "project" entity by ID or all projects. Validated with ValidateApiKey middleware.
+// - POST /api/v1/projects: Creates a new "project" entity as a draft. Validated with ValidateApiKey middleware.
+// - GET /api/v1/projects: Retrieves the published projects in display order. Validated with ValidateApiKey middleware, and ValidateAdminKey when "drafts" is true.
+// - GET /api/v1/projects/:id: Retrieves a published "project" entity by ID or slug. Validated with ValidateApiKey middleware, and ValidateAdminKey when "drafts" is true.
+// - PUT /api/v1/projects/:id: Replaces the content of a "project" entity. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - PUT /api/v1/projects/order: Sets the display order and the featured projects. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/projects/:id/link-checks: Retrieves the link check history of a "project" entity. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - PATCH /api/v1/projects/:id/status: Changes the publication status of a "project" entity. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - POST /api/v1/projects/:id/images: Adds an image to the gallery of a "project" entity. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - PUT /api/v1/projects/:id/images/order: Sets the display order of the gallery. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - PATCH /api/v1/projects/:id/images/:imageId: Changes the alt text, caption or cover flag of an image. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - DELETE /api/v1/projects/:id/images/:imageId: Removes an image from the gallery. Validated with ValidateApiKey and ValidateAdminKey middleware.
+// - GET /api/v1/projects/translations/missing: Reports the missing translations. Validated with ValidateApiKey and ValidateAdminKey middleware.
+//
+// Reads return the translated fields in the locale chosen from the "lang" query parameter or the Accept-Language header.
+// Single entries embed their gallery, while lists only carry the cover of each entry in its image fields.
+// Drafts and archived entries are only returned when the "drafts" query parameter is true, which requires the admin key.
+// Such admin reads, and the responses of admin changes, flag the links found broken by the link checker.
+//
+// The following legacy routes are kept as deprecated aliases and send Deprecation and Sunset headers:
+// - POST /addProject: Alias of POST /api/v1/projects.
+// - GET /project: Alias of GET /api/v1/projects; also accepts an "id" query parameter.
+//
+// Every route validates its parameters and body against the OpenAPI document with ValidateRequest middleware.
 //
 // Parameters:
 // - router: The Gin router instance to configure.
+// - handler: The handler serving the endpoints.
 //
 // Example:
 //   router := gin.Default()
-//   routes.SetupProjectRoutes(router)
-func SetupProjectRoutes(router *gin.Engine) {
-	router.POST("/addProject", middlewares.ValidateApiKey(), projectcontrollers.CreateProject)
-	router.GET("/project", middlewares.ValidateApiKey(), projectcontrollers.GetProject)
+//   routes.SetupProjectRoutes(router, projectcontrollers.NewHandler(service, locales, limits))
+func SetupProjectRoutes(router *gin.Engine, handler *projectcontrollers.Handler) {
+	v1 := router.Group(APIPrefix, middlewares.ValidateApiKey())
+	v1.POST("/projects", validateRequest(), handler.CreateProject)
+	v1.GET("/projects", middlewares.ValidateAdminKeyWhen(projectcontrollers.DraftsParameter), validateRequest(), handler.GetProject)
+	v1.GET("/projects/:id", middlewares.ValidateAdminKeyWhen(projectcontrollers.DraftsParameter), validateRequest(), handler.GetProjectByID)
+	v1.PUT("/projects/:id", middlewares.ValidateAdminKey(), validateRequest(), handler.UpdateProject)
+	v1.PUT("/projects/order", middlewares.ValidateAdminKey(), validateRequest(), handler.ReorderProjects)
+	v1.GET("/projects/:id/link-checks", middlewares.ValidateAdminKey(), validateRequest(), handler.GetProjectLinkChecks)
+	v1.PATCH("/projects/:id/status", middlewares.ValidateAdminKey(), validateRequest(), handler.UpdateProjectStatus)
+	v1.POST("/projects/:id/images", middlewares.ValidateAdminKey(), validateRequest(), handler.AddProjectImage)
+	v1.PUT("/projects/:id/images/order", middlewares.ValidateAdminKey(), validateRequest(), handler.ReorderProjectImages)
+	v1.PATCH("/projects/:id/images/:imageId", middlewares.ValidateAdminKey(), validateRequest(), handler.UpdateProjectImage)
+	v1.DELETE("/projects/:id/images/:imageId", middlewares.ValidateAdminKey(), validateRequest(), handler.DeleteProjectImage)
+	v1.GET("/projects/translations/missing", middlewares.ValidateAdminKey(), validateRequest(), handler.GetMissingTranslations)
+
+	router.POST("/addProject", deprecated(APIPrefix+"/projects"), middlewares.ValidateApiKey(), validateRequest(), handler.CreateProject)
+	router.GET("/project", deprecated(APIPrefix+"/projects"), middlewares.ValidateApiKey(),
+		middlewares.ValidateAdminKeyWhen(projectcontrollers.DraftsParameter), validateRequest(), handler.GetProject)
+}
+
+// draftsParameter is the admin-only query parameter of the read endpoints that also returns draft and archived entries.
+var draftsParameter = openapi.Query(projectcontrollers.DraftsParameter,
+	"Also return draft and archived projects; requires the admin key", openapi.Boolean())
+
+// featuredParameter is the query parameter of the list endpoints that only returns featured entries.
+var featuredParameter = openapi.Query("featured", "Only return featured projects", openapi.Boolean())
+
+// projectKeyParameter is the path parameter identifying a project by its ID or its slug.
+var projectKeyParameter = openapi.Path("id", "ID or slug of the project; a previous slug is redirected to the current one",
+	&openapi.Schema{Type: "string", Pattern: "^([1-9][0-9]*|" + strings.Trim(slugs.Pattern, "^$") + ")$"})
+
+// imageIDParameter is the path parameter identifying an image of a gallery.
+var imageIDParameter = openapi.Path("imageId", "ID of the image", openapi.Integer(1))
+
+// projectOperations describes the "project" routes in the OpenAPI document.
+func projectOperations() []openapi.Operation {
+	create := openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        APIPrefix + "/projects",
+		ID:          "createProject",
+		Summary:     "Create a project",
+		Description: "Creates a draft; it is published or scheduled by changing its status, which requires the admin key.",
+		Tags:        []string{"Projects"},
+		Security:    []string{openapi.APIKey},
+		Request:     projectmodels.Project{},
+		Status:      http.StatusCreated,
+		Response:    projectmodels.Project{},
+		Errors:      writeErrors,
+	}
+	list := openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    APIPrefix + "/projects",
+		ID:      "listProjects",
+		Summary: "List projects",
+		Description: "Returns the summary of the projects; their write-up sections and metrics are only returned " +
+			"by the endpoint of a single project.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey},
+		Parameters: append([]openapi.Parameter{idQueryParameter, featuredParameter, draftsParameter}, localeParameters...),
+		Status:     http.StatusOK,
+		Response:   []projectmodels.Project{},
+		Errors:     readErrors,
+	}
+	get := openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    APIPrefix + "/projects/:id",
+		ID:      "getProject",
+		Summary: "Get a project",
+		Description: "Finds the project by its ID or its slug and returns it with its write-up sections, " +
+			"their Markdown bodies rendered into HTML, and its key metrics. A previous slug of a renamed project is answered " +
+			"with a redirect to its current slug, keeping the query string.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey},
+		Parameters: append([]openapi.Parameter{projectKeyParameter, draftsParameter}, localeParameters...),
+		Status:     http.StatusOK,
+		Response:   projectmodels.Project{},
+		Redirects:  []int{http.StatusMovedPermanently},
+		Errors:     readErrors,
+	}
+	update := openapi.Operation{
+		Method:  http.MethodPut,
+		Path:    APIPrefix + "/projects/:id",
+		ID:      "updateProject",
+		Summary: "Update a project",
+		Description: "Replaces the image, titles, description, links, dates, role, team size, lifecycle, write-up sections, metrics " +
+			"and translations of the project. " +
+			"The status, display order and gallery are changed with their own endpoints and left unchanged. " +
+			"A new title gives the project a new slug, and requests for the previous slug are redirected to it.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter},
+		Request:    projectmodels.Project{},
+		Status:     http.StatusOK,
+		Response:   projectmodels.Project{},
+		Errors:     append([]int{http.StatusConflict}, readErrors...),
+	}
+	reorder := openapi.Operation{
+		Method:  http.MethodPut,
+		Path:    APIPrefix + "/projects/order",
+		ID:      "reorderProjects",
+		Summary: "Curate the display order of projects",
+		Description: "Moves the listed projects to the front of the display order, followed by the others in their previous order, " +
+			"and replaces the featured projects when featured is given. Responds with every project, drafts included, in the new order.",
+		Tags:     []string{"Projects"},
+		Security: []string{openapi.APIKey, openapi.AdminKey},
+		Request:  projectmodels.ProjectOrder{},
+		Status:   http.StatusOK,
+		Response: []projectmodels.Project{},
+		Errors:   []int{http.StatusServiceUnavailable},
+	}
+	linkChecks := openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    APIPrefix + "/projects/:id/link-checks",
+		ID:      "listProjectLinkChecks",
+		Summary: "List the link checks of a project",
+		Description: "Returns the status history of the repository, image, demo, documentation and typed links of the project, " +
+			"the most recent check first. Links are checked by a background job.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter},
+		Status:     http.StatusOK,
+		Response:   []projectmodels.ProjectLinkCheck{},
+		Errors:     readErrors,
+	}
+	updateStatus := openapi.Operation{
+		Method:      http.MethodPatch,
+		Path:        APIPrefix + "/projects/:id/status",
+		ID:          "updateProjectStatus",
+		Summary:     "Change the publication status of a project",
+		Description: "Only published projects are public. A draft with publishAt is published by the scheduler once that time has passed.",
+		Tags:        []string{"Projects"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters:  []openapi.Parameter{idParameter},
+		Request:     projectmodels.ProjectStatus{},
+		Status:      http.StatusOK,
+		Response:    projectmodels.Project{},
+		Errors:      readErrors,
+	}
+	addImage := openapi.Operation{
+		Method:  http.MethodPost,
+		Path:    APIPrefix + "/projects/:id/images",
+		ID:      "addProjectImage",
+		Summary: "Add an image to the gallery of a project",
+		Description: "Stores a JPEG, PNG, GIF or WebP image, detected from its content, at the end of the gallery of the project. " +
+			"The image is encoded again without its metadata; WebP images stay WebP. " +
+			"Thumbnail, medium and large variants, at most 320, 768 and 1600 pixels wide, are generated for srcset " +
+			"together with a BlurHash and the dominant color as placeholders. " +
+			"The first image of a gallery always becomes its cover.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter},
+		Form: []openapi.FormField{
+			{Name: "image", Description: "Image file", Required: true, File: true},
+			{Name: "alt", Description: "Text alternative of the image; defaults to the file name without its extension", Schema: openapi.MaxString(255)},
+			{Name: "caption", Description: "Caption shown below the image", Schema: openapi.MaxString(1000)},
+			{Name: "cover", Description: "Whether the image becomes the cover of the project", Schema: openapi.Boolean()},
+		},
+		Status:   http.StatusCreated,
+		Response: projectmodels.Project{},
+		Errors: []int{
+			http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusServiceUnavailable,
+		},
+	}
+	reorderImages := openapi.Operation{
+		Method:      http.MethodPut,
+		Path:        APIPrefix + "/projects/:id/images/order",
+		ID:          "reorderProjectImages",
+		Summary:     "Reorder the gallery of a project",
+		Description: "Sets the display order of the gallery; the body lists every image of the gallery exactly once.",
+		Tags:        []string{"Projects"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Parameters:  []openapi.Parameter{idParameter},
+		Request:     projectmodels.ProjectImageOrder{},
+		Status:      http.StatusOK,
+		Response:    projectmodels.Project{},
+		Errors:      readErrors,
+	}
+	updateImage := openapi.Operation{
+		Method:  http.MethodPatch,
+		Path:    APIPrefix + "/projects/:id/images/:imageId",
+		ID:      "updateProjectImage",
+		Summary: "Update an image of the gallery of a project",
+		Description: "Changes the alt text, caption or cover flag of an image; omitted fields are left unchanged. " +
+			"Setting cover to true replaces the current cover, which cannot be unset otherwise.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter, imageIDParameter},
+		Request:    projectmodels.ProjectImageUpdate{},
+		Status:     http.StatusOK,
+		Response:   projectmodels.Project{},
+		Errors:     readErrors,
+	}
+	deleteImage := openapi.Operation{
+		Method:  http.MethodDelete,
+		Path:    APIPrefix + "/projects/:id/images/:imageId",
+		ID:      "deleteProjectImage",
+		Summary: "Remove an image from the gallery of a project",
+		Description: "Removes the image and its variants from the gallery and the storage. " +
+			"When the cover is removed, the first remaining image becomes the cover.",
+		Tags:       []string{"Projects"},
+		Security:   []string{openapi.APIKey, openapi.AdminKey},
+		Parameters: []openapi.Parameter{idParameter, imageIDParameter},
+		Status:     http.StatusNoContent,
+		Errors:     readErrors,
+	}
+	missingTranslations := openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        APIPrefix + "/projects/translations/missing",
+		ID:          "listMissingProjectTranslations",
+		Summary:     "List missing project translations",
+		Description: "Lists, for every project and translated locale, the fields that are not translated.",
+		Tags:        []string{"Projects"},
+		Security:    []string{openapi.APIKey, openapi.AdminKey},
+		Status:      http.StatusOK,
+		Response:    []i18n.MissingTranslation{},
+		Errors:      []int{http.StatusServiceUnavailable},
+	}
+
+	return []openapi.Operation{
+		create,
+		list,
+		get,
+		update,
+		reorder,
+		linkChecks,
+		updateStatus,
+		addImage,
+		reorderImages,
+		updateImage,
+		deleteImage,
+		missingTranslations,
+		legacyOperation(create, "/addProject", "legacyCreateProject"),
+		legacyOperation(list, "/project", "legacyListProjects"),
+	}
 }
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/EkoAgustina/go-ms-portfolio/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// UploadsPath is the path the files of the local storage backend are served from.
+const UploadsPath = "/uploads"
+
+// SetupUploadRoutes configures the route serving uploaded files on the Gin router.
+// This function sets up the following route:
+// - GET /uploads/*filepath: Serves the files stored by the local storage backend.
+//
+// The route is only registered for the local backend; objects of the S3 backend are served by the object store.
+// Uploaded images are public, so the route is not validated with ValidateApiKey middleware.
+//
+// Parameters:
+// - router: The Gin router instance to configure.
+// - store: The storage backend of the uploaded files.
+//
+// Example:
+//
+//	router := gin.Default()
+//	routes.SetupUploadRoutes(router, storage.Load())
+func SetupUploadRoutes(router *gin.Engine, store storage.Storage) {
+	local, ok := store.(*storage.Local)
+	if !ok {
+		return
+	}
+	router.Static(UploadsPath, local.Dir())
+}
@@ -0,0 +1,201 @@
+// Package aboutservices implements the business logic of the "About" document.
+package aboutservices
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
+	"github.com/EkoAgustina/go-ms-portfolio/markdown"
+	"github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/aboutRepositories"
+)
+
+// Service saves and reads the "About" document and its revisions. The document is returned in
+// the requested locale, with its Markdown content rendered into HTML. Reads of the document are
+// served from the cache when possible, and every save publishes the new document in every locale,
+// rendering included, to the cache.
+// Errors are the repository errors, so callers can test them with errors.Is.
+type Service interface {
+	// Current returns the current document in locale.
+	Current(ctx context.Context, locale string) (aboutmodels.About, error)
+	// FindByID returns the entry with the given ID in locale.
+	FindByID(ctx context.Context, id uint, locale string) (aboutmodels.About, error)
+	// Save replaces the current document with about as a new revision saved by author,
+	// and reloads the saved document, in the default locale, into about.
+	Save(ctx context.Context, about *aboutmodels.About, author string) (aboutmodels.AboutRevision, error)
+	// Revisions returns every revision without its snapshot, the most recent first.
+	Revisions(ctx context.Context) ([]aboutmodels.AboutRevision, error)
+	// Revision returns the revision with the given number, with its snapshot rendered like the document.
+	Revision(ctx context.Context, number int) (aboutmodels.AboutRevision, error)
+	// Diff returns the changes made to the document between the revisions from and to.
+	Diff(ctx context.Context, from int, to int) ([]aboutmodels.Change, error)
+	// Rollback saves the document of the revision with the given number as a new revision saved by author.
+	Rollback(ctx context.Context, number int, author string) (aboutmodels.AboutRevision, error)
+	// MissingTranslations reports the translated locales lacking the content of the current document.
+	MissingTranslations(ctx context.Context) ([]i18n.MissingTranslation, error)
+}
+
+// service is the default implementation of Service.
+type service struct {
+	repo    aboutrepositories.Repository
+	cache   cache.Cache
+	locales *i18n.Locales
+}
+
+// NewService returns a Service that stores the document in repo, caches reads in c and
+// translates the document into locales.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewService(repo aboutrepositories.Repository, c cache.Cache, locales *i18n.Locales) Service {
+	if repo == nil || c == nil || locales == nil {
+		panic("aboutservices: nil dependency")
+	}
+	return &service{repo: repo, cache: c, locales: locales}
+}
+
+func (s *service) Current(ctx context.Context, locale string) (aboutmodels.About, error) {
+	return cache.Load(ctx, s.cache, currentKey(locale), func(ctx context.Context) (aboutmodels.About, error) {
+		about, err := s.repo.Current(ctx)
+		if err != nil {
+			return about, err
+		}
+		return s.localize(about, locale)
+	})
+}
+
+func (s *service) FindByID(ctx context.Context, id uint, locale string) (aboutmodels.About, error) {
+	return cache.Load(ctx, s.cache, cacheKey(id, locale), func(ctx context.Context) (aboutmodels.About, error) {
+		about, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return about, err
+		}
+		return s.localize(about, locale)
+	})
+}
+
+func (s *service) Save(ctx context.Context, about *aboutmodels.About, author string) (aboutmodels.AboutRevision, error) {
+	about.Normalize()
+	return s.publish(ctx, about, author, nil)
+}
+
+func (s *service) Revisions(ctx context.Context) ([]aboutmodels.AboutRevision, error) {
+	return s.repo.Revisions(ctx)
+}
+
+func (s *service) Revision(ctx context.Context, number int) (aboutmodels.AboutRevision, error) {
+	revision, err := s.repo.Revision(ctx, number)
+	if err != nil {
+		return revision, err
+	}
+	return revision, render(revision.Snapshot)
+}
+
+func (s *service) MissingTranslations(ctx context.Context) ([]i18n.MissingTranslation, error) {
+	missing := []i18n.MissingTranslation{}
+	about, err := s.repo.Current(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return missing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, locale := range s.locales.Translated() {
+		var translation aboutmodels.AboutTranslation
+		for _, t := range about.Translations {
+			if t.Locale == locale {
+				translation = t
+			}
+		}
+		if about.Content != "" && translation.Content == "" {
+			missing = append(missing, i18n.MissingTranslation{
+				Entity: "about",
+				ID:     about.ID,
+				Locale: locale,
+				Fields: []string{"content"},
+			})
+		}
+	}
+	return missing, nil
+}
+
+func (s *service) Diff(ctx context.Context, from int, to int) ([]aboutmodels.Change, error) {
+	older, err := s.repo.Revision(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.repo.Revision(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	return diff(older.Snapshot, newer.Snapshot)
+}
+
+func (s *service) Rollback(ctx context.Context, number int, author string) (aboutmodels.AboutRevision, error) {
+	revision, err := s.repo.Revision(ctx, number)
+	if err != nil {
+		return aboutmodels.AboutRevision{}, err
+	}
+
+	about := *revision.Snapshot
+	about.Normalize()
+	return s.publish(ctx, &about, author, &number)
+}
+
+// publish saves about as a new revision and replaces the cached document in every locale in
+// one step, removing the cached copies of the entry read by ID.
+func (s *service) publish(ctx context.Context, about *aboutmodels.About, author string, rolledBackFrom *int) (aboutmodels.AboutRevision, error) {
+	revision, err := s.repo.Save(ctx, about, author, rolledBackFrom)
+	if err != nil {
+		return aboutmodels.AboutRevision{}, err
+	}
+
+	values := map[string]interface{}{}
+	var stale []string
+	for _, locale := range s.locales.Supported() {
+		localized, err := s.localize(*about, locale)
+		if err != nil {
+			return aboutmodels.AboutRevision{}, err
+		}
+		values[currentKey(locale)] = localized
+		stale = append(stale, cacheKey(about.ID, locale))
+	}
+	cache.Publish(ctx, s.cache, values, stale...)
+
+	*about = values[currentKey(s.locales.Default())].(aboutmodels.About)
+	return revision, nil
+}
+
+// localize returns about with its translated fields in locale, rendered into HTML.
+func (s *service) localize(about aboutmodels.About, locale string) (aboutmodels.About, error) {
+	content := map[string]string{s.locales.Default(): about.Content}
+	for _, translation := range about.Translations {
+		content[translation.Locale] = translation.Content
+	}
+	about.Content = s.locales.Pick(locale, content)
+	about.Locale = locale
+	return about, render(&about)
+}
+
+// render sets the HTML rendering of the Markdown content of about.
+func render(about *aboutmodels.About) error {
+	html, err := markdown.Render(about.Content)
+	if err != nil {
+		return err
+	}
+	about.ContentHTML = html
+	return nil
+}
+
+// currentKey returns the cache key of the current document in locale.
+func currentKey(locale string) string {
+	return "about:current:" + locale
+}
+
+// cacheKey returns the cache key of a single entry in locale.
+func cacheKey(id uint, locale string) string {
+	return "about:" + strconv.FormatUint(uint64(id), 10) + ":" + locale
+}
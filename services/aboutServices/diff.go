@@ -0,0 +1,135 @@
+package aboutservices
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/EkoAgustina/go-ms-portfolio/models/aboutModels"
+)
+
+// ignoredFields are the JSON members left out of diffs: they are generated on every save or
+// rendered from other members, and do not describe a change made by the author.
+var ignoredFields = map[string]bool{
+	"ID":          true,
+	"CreatedAt":   true,
+	"UpdatedAt":   true,
+	"DeletedAt":   true,
+	"id":          true,
+	"revision":    true,
+	"contentHtml": true,
+	"position":    true,
+}
+
+// diff compares the JSON representations of two documents. Entries of the collections are
+// compared by their position, and a member or entry missing on one side is reported as a
+// single change holding the whole value.
+func diff(from *aboutmodels.About, to *aboutmodels.About) ([]aboutmodels.Change, error) {
+	older, err := toJSONValue(from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := toJSONValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []aboutmodels.Change{}
+	compare("", older, newer, &changes)
+	return changes, nil
+}
+
+// toJSONValue converts v into the generic value produced by decoding its JSON representation.
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// compare appends the differences between from and to, found at path, to changes.
+func compare(path string, from interface{}, to interface{}, changes *[]aboutmodels.Change) {
+	switch older := from.(type) {
+	case map[string]interface{}:
+		if newer, ok := to.(map[string]interface{}); ok {
+			for _, key := range keys(older, newer) {
+				member := key
+				if path != "" {
+					member = path + "." + key
+				}
+				olderValue, inOlder := older[key]
+				newerValue, inNewer := newer[key]
+				switch {
+				case !inOlder:
+					*changes = append(*changes, aboutmodels.Change{Path: member, Op: aboutmodels.ChangeAdded, To: strip(newerValue)})
+				case !inNewer:
+					*changes = append(*changes, aboutmodels.Change{Path: member, Op: aboutmodels.ChangeRemoved, From: strip(olderValue)})
+				default:
+					compare(member, olderValue, newerValue, changes)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if newer, ok := to.([]interface{}); ok {
+			for i := 0; i < len(older) || i < len(newer); i++ {
+				entry := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(older):
+					*changes = append(*changes, aboutmodels.Change{Path: entry, Op: aboutmodels.ChangeAdded, To: strip(newer[i])})
+				case i >= len(newer):
+					*changes = append(*changes, aboutmodels.Change{Path: entry, Op: aboutmodels.ChangeRemoved, From: strip(older[i])})
+				default:
+					compare(entry, older[i], newer[i], changes)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, aboutmodels.Change{Path: path, Op: aboutmodels.ChangeChanged, From: strip(from), To: strip(to)})
+	}
+}
+
+// keys returns the members of a and b that are not ignored, sorted.
+func keys(a map[string]interface{}, b map[string]interface{}) []string {
+	var keys []string
+	for key := range a {
+		if !ignoredFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok && !ignoredFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// strip removes the ignored members from value.
+func strip(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		stripped := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			if !ignoredFields[key] {
+				stripped[key] = strip(member)
+			}
+		}
+		return stripped
+	case []interface{}:
+		stripped := make([]interface{}, len(v))
+		for i, entry := range v {
+			stripped[i] = strip(entry)
+		}
+		return stripped
+	}
+	return value
+}
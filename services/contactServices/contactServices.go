@@ -0,0 +1,106 @@
+// Package contactservices implements the business logic of "Contact" entries.
+package contactservices
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/contactRepositories"
+)
+
+// Mailer sends email notifications.
+type Mailer interface {
+	SendEmail(ctx context.Context, to string, subject string, body string)
+}
+
+// Service creates, triages and reads "Contact" entries. Reads are served from the cache when possible.
+// Errors are the repository errors, so callers can test them with errors.Is.
+type Service interface {
+	// Create stores a new message as untriaged and notifies the site owner once it is committed.
+	Create(ctx context.Context, contact *contactmodels.Contact) error
+	// FindByID returns the message with the given ID.
+	FindByID(ctx context.Context, id uint) (contactmodels.Contact, error)
+	// FindAll returns every message.
+	FindAll(ctx context.Context) ([]contactmodels.Contact, error)
+	// UpdateStatus changes the status of the message with the given ID and returns the updated message.
+	UpdateStatus(ctx context.Context, id uint, status string) (contactmodels.Contact, error)
+	// Export calls fn for every batch of messages matching filter, in batches of batchSize rows.
+	Export(ctx context.Context, filter contactrepositories.Filter, batchSize int, fn func(batch []contactmodels.Contact) error) error
+}
+
+// service is the default implementation of Service.
+type service struct {
+	repo        contactrepositories.Repository
+	cache       cache.Cache
+	mailer      Mailer
+	emailTarget string
+}
+
+// NewService returns a Service that stores messages in repo, caches reads in c
+// and notifies emailTarget of new messages through mailer.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewService(repo contactrepositories.Repository, c cache.Cache, mailer Mailer, emailTarget string) Service {
+	if repo == nil || c == nil || mailer == nil {
+		panic("contactservices: nil dependency")
+	}
+	return &service{repo: repo, cache: c, mailer: mailer, emailTarget: emailTarget}
+}
+
+func (s *service) Create(ctx context.Context, contact *contactmodels.Contact) error {
+	// New messages always start untriaged, whatever the visitor sent
+	contact.Status = contactmodels.StatusNew
+	contact.AnonymizedAt = nil
+
+	// The notification is only sent once the message is committed
+	if err := s.repo.Create(ctx, contact); err != nil {
+		return err
+	}
+	cache.Invalidate(ctx, s.cache, "contact:all")
+
+	emailMsg := fmt.Sprintf(`Hi,
+
+You received a new message from a Portfolio Website visitor:
+
+Name: %s
+Email: %s
+Message: %s
+
+Thank you.`, contact.Name, contact.Email, contact.Message)
+
+	s.mailer.SendEmail(ctx, s.emailTarget, contact.Subject, emailMsg)
+	return nil
+}
+
+func (s *service) FindByID(ctx context.Context, id uint) (contactmodels.Contact, error) {
+	return cache.Load(ctx, s.cache, cacheKey(id), func(ctx context.Context) (contactmodels.Contact, error) {
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+func (s *service) FindAll(ctx context.Context) ([]contactmodels.Contact, error) {
+	return cache.Load(ctx, s.cache, "contact:all", s.repo.FindAll)
+}
+
+func (s *service) UpdateStatus(ctx context.Context, id uint, status string) (contactmodels.Contact, error) {
+	contact, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return contact, err
+	}
+	if err := s.repo.UpdateStatus(ctx, &contact, status); err != nil {
+		return contact, err
+	}
+	cache.Invalidate(ctx, s.cache, "contact:all", cacheKey(id))
+	return contact, nil
+}
+
+func (s *service) Export(ctx context.Context, filter contactrepositories.Filter, batchSize int, fn func(batch []contactmodels.Contact) error) error {
+	return s.repo.FindInBatches(ctx, filter, batchSize, fn)
+}
+
+// cacheKey returns the cache key of a single message.
+func cacheKey(id uint) string {
+	return "contact:" + strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,80 @@
+package contactservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/models/contactModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/contactRepositories"
+)
+
+// fakeRepository stores entries in memory. Methods a test does not use panic through the nil interface.
+type fakeRepository struct {
+	contactrepositories.Repository
+	err     error
+	created []contactmodels.Contact
+}
+
+func (r *fakeRepository) Create(ctx context.Context, contact *contactmodels.Contact) error {
+	if r.err != nil {
+		return r.err
+	}
+	contact.ID = uint(len(r.created) + 1)
+	r.created = append(r.created, *contact)
+	return nil
+}
+
+// fakeCache records the deleted keys.
+type fakeCache struct {
+	cache.Cache
+	deleted []string
+}
+
+func (c *fakeCache) Delete(ctx context.Context, keys ...string) error {
+	c.deleted = append(c.deleted, keys...)
+	return nil
+}
+
+// fakeMailer records the sent emails.
+type fakeMailer struct {
+	subjects []string
+}
+
+func (m *fakeMailer) SendEmail(ctx context.Context, to string, subject string, body string) {
+	m.subjects = append(m.subjects, subject)
+}
+
+func TestCreate(t *testing.T) {
+	repo, c, mailer := &fakeRepository{}, &fakeCache{}, &fakeMailer{}
+	service := NewService(repo, c, mailer, "owner@example.com")
+
+	contact := contactmodels.Contact{Subject: "Hello", Status: contactmodels.StatusArchived}
+	if err := service.Create(context.Background(), &contact); err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if contact.ID == 0 || contact.Status != contactmodels.StatusNew {
+		t.Errorf("created contact = %+v, want a stored untriaged message", contact)
+	}
+	if len(mailer.subjects) != 1 || mailer.subjects[0] != "Hello" {
+		t.Errorf("sent emails = %v, want one notification", mailer.subjects)
+	}
+	if len(c.deleted) != 1 || c.deleted[0] != "contact:all" {
+		t.Errorf("deleted cache keys = %v, want the cached list", c.deleted)
+	}
+}
+
+func TestCreateFailureSendsNoEmail(t *testing.T) {
+	repo, c, mailer := &fakeRepository{err: repositories.ErrUnavailable}, &fakeCache{}, &fakeMailer{}
+	service := NewService(repo, c, mailer, "owner@example.com")
+
+	err := service.Create(context.Background(), &contactmodels.Contact{Subject: "Hello"})
+	if !errors.Is(err, repositories.ErrUnavailable) {
+		t.Errorf("Create() returned %v, want the repository error", err)
+	}
+	if len(mailer.subjects) != 0 || len(c.deleted) != 0 {
+		t.Errorf("sent emails = %v and deleted keys = %v, want neither for an unsaved message", mailer.subjects, c.deleted)
+	}
+}
@@ -0,0 +1,169 @@
+package projectservices
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/linkcheck"
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/projectRepositories"
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+)
+
+// Mailer sends email notifications.
+type Mailer interface {
+	SendEmail(ctx context.Context, to string, subject string, body string)
+}
+
+// LinkCheckPolicy holds the settings of the link checker job.
+type LinkCheckPolicy struct {
+	HistoryDays int    // Checks older than this many days are removed
+	EmailTarget string // Recipient of the digest of newly broken links; no digest is sent when empty
+}
+
+// LoadLinkCheckPolicy loads the settings of the link checker job from environment variables.
+//
+// Environment Variables:
+// - LINK_CHECK_HISTORY_DAYS: Days the status history of links is kept (default 90).
+// - LINK_CHECK_EMAIL_TARGET: Recipient of the digest of newly broken links (default EMAIL_TARGET).
+func LoadLinkCheckPolicy() LinkCheckPolicy {
+	return LinkCheckPolicy{
+		HistoryDays: utils.LoadEnvInt("LINK_CHECK_HISTORY_DAYS", 90),
+		EmailTarget: utils.LoadEnvDefault("LINK_CHECK_EMAIL_TARGET", utils.LoadEnvDefault("EMAIL_TARGET", "")),
+	}
+}
+
+// LinkCheckJob checks the links of every "Project" entry, drafts included: the repository link,
+// the image given as a URL, the demo and documentation URLs and the typed links.
+// Every result is recorded in the status history of the link, and the links that broke since
+// their previous check are sent as a digest by email.
+type LinkCheckJob struct {
+	repo    projectrepositories.Repository
+	checker *linkcheck.Checker
+	mailer  Mailer
+	policy  LinkCheckPolicy
+}
+
+// NewLinkCheckJob returns a LinkCheckJob that checks the entries of repo with checker and sends digests through mailer.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewLinkCheckJob(repo projectrepositories.Repository, checker *linkcheck.Checker, mailer Mailer, policy LinkCheckPolicy) *LinkCheckJob {
+	if repo == nil || checker == nil || mailer == nil {
+		panic("projectservices: nil dependency for the link check job")
+	}
+	return &LinkCheckJob{repo: repo, checker: checker, mailer: mailer, policy: policy}
+}
+
+// Run checks every link once. It is meant to be registered on a jobs.Scheduler.
+func (j *LinkCheckJob) Run(ctx context.Context) error {
+	projects, err := j.repo.FindAll(ctx, projectrepositories.Filter{Drafts: true})
+	if err != nil {
+		return err
+	}
+
+	// A URL used several times is only requested once
+	var urls []string
+	seen := map[string]bool{}
+	for _, project := range projects {
+		for _, link := range checkedLinks(project) {
+			if !seen[link.url] {
+				seen[link.url] = true
+				urls = append(urls, link.url)
+			}
+		}
+	}
+	results := map[string]linkcheck.Result{}
+	for _, result := range j.checker.Check(ctx, urls) {
+		results[result.URL] = result
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	previous, err := j.repo.LatestLinkChecks(ctx, nil)
+	if err != nil {
+		return err
+	}
+	wasBroken := map[string]bool{}
+	for _, check := range previous {
+		wasBroken[strconv.FormatUint(uint64(check.ProjectID), 10)+" "+check.URL] = check.Broken
+	}
+
+	now := time.Now()
+	var checks []projectmodels.ProjectLinkCheck
+	var digest []string
+	broken := 0
+	for _, project := range projects {
+		for _, link := range checkedLinks(project) {
+			result := results[link.url]
+			check := projectmodels.ProjectLinkCheck{
+				ProjectID: project.ID, Field: link.field, URL: link.url,
+				Status: result.Status, Error: result.Error, Broken: result.Broken, CheckedAt: now,
+			}
+			checks = append(checks, check)
+			if !check.Broken {
+				continue
+			}
+			broken++
+			if !wasBroken[strconv.FormatUint(uint64(project.ID), 10)+" "+link.url] {
+				digest = append(digest, digestLine(project, check))
+			}
+		}
+	}
+
+	if err := j.repo.RecordLinkChecks(ctx, checks, now.AddDate(0, 0, -j.policy.HistoryDays)); err != nil {
+		return err
+	}
+	log.Printf("Checked %d links of %d projects: %d broken, %d newly broken", len(checks), len(projects), broken, len(digest))
+
+	if len(digest) > 0 && j.policy.EmailTarget != "" {
+		body := "Hi,\n\nThe link checker found new broken links in the portfolio projects:\n\n" +
+			strings.Join(digest, "\n") + "\n\nThank you."
+		j.mailer.SendEmail(ctx, j.policy.EmailTarget, fmt.Sprintf("%d broken project links", len(digest)), body)
+	}
+	return nil
+}
+
+// digestLine describes the broken link of check for the digest email.
+func digestLine(project projectmodels.Project, check projectmodels.ProjectLinkCheck) string {
+	reason := check.Error
+	if check.Status != 0 {
+		reason = "HTTP " + strconv.Itoa(check.Status)
+	}
+	return fmt.Sprintf("- Project %d (%s), %s: %s (%s)", project.ID, project.ProjectTitle, check.Field, check.URL, reason)
+}
+
+// link is a URL found in a field of an entry.
+type link struct {
+	field string // JSON name of the field, e.g. links[0].url
+	url   string
+}
+
+// checkedLinks returns the links of project checked by the link checker: the absolute HTTP URLs among
+// its repository link, image, demo and documentation URLs and typed links, in this order.
+// Uploaded images are served by the application or its storage and are not checked.
+func checkedLinks(project projectmodels.Project) []link {
+	candidates := []link{
+		{"repositoryLink", project.RepositoryLink},
+		{"image", project.Image},
+		{"demoUrl", project.DemoURL},
+		{"documentationUrl", project.DocumentationURL},
+	}
+	for i, projectLink := range project.Links {
+		candidates = append(candidates, link{"links[" + strconv.Itoa(i) + "].url", projectLink.URL})
+	}
+
+	var links []link
+	for _, candidate := range candidates {
+		u, err := url.Parse(candidate.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		links = append(links, candidate)
+	}
+	return links
+}
@@ -0,0 +1,573 @@
+// Package projectservices implements the business logic of "Project" entries.
+package projectservices
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/EkoAgustina/go-ms-portfolio/cache"
+	"github.com/EkoAgustina/go-ms-portfolio/config/metrics"
+	"github.com/EkoAgustina/go-ms-portfolio/githosting"
+	"github.com/EkoAgustina/go-ms-portfolio/i18n"
+	"github.com/EkoAgustina/go-ms-portfolio/images"
+	"github.com/EkoAgustina/go-ms-portfolio/markdown"
+	"github.com/EkoAgustina/go-ms-portfolio/models/projectModels"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories"
+	"github.com/EkoAgustina/go-ms-portfolio/repositories/projectRepositories"
+	"github.com/EkoAgustina/go-ms-portfolio/storage"
+)
+
+// Service creates and reads "Project" entries. Entries are returned in the requested locale with
+// their Markdown description rendered into HTML, and reads are served from the cache, rendering
+// included, when possible.
+// Errors are the repository errors, so callers can test them with errors.Is.
+type Service interface {
+	// Create stores a new entry as a draft and returns it in the default locale.
+	Create(ctx context.Context, project *projectmodels.Project) error
+	// Update replaces the content and the translations of the entry with project.ID and returns it in the default locale.
+	// A new title gives the entry a new slug, while requests for the previous one are redirected, see FindBySlug.
+	Update(ctx context.Context, project *projectmodels.Project) error
+	// FindByID returns the entry with the given ID with its write-up, metrics and gallery in locale.
+	// Entries that are not published are reported as repositories.ErrNotFound unless drafts is true,
+	// which also flags the broken links of the entry.
+	FindByID(ctx context.Context, id uint, locale string, drafts bool) (projectmodels.Project, error)
+	// FindBySlug returns the entry with the given slug like FindByID. For a previous slug of an entry,
+	// it returns a *MovedError holding the current one instead.
+	FindBySlug(ctx context.Context, slug string, locale string, drafts bool) (projectmodels.Project, error)
+	// BackfillSlugs generates the slug of the entries created before slugs existed.
+	BackfillSlugs(ctx context.Context) error
+	// FindAll returns the entries selected by filter in locale, without the write-up, metrics and gallery
+	// but with the fields of its cover.
+	// With filter.Drafts, the broken links of the entries are flagged.
+	FindAll(ctx context.Context, locale string, filter projectrepositories.Filter) ([]projectmodels.Project, error)
+	// Reorder curates the display order and the featured entries, see projectrepositories.Repository.Reorder,
+	// and returns every entry in the default locale and the new order, drafts included.
+	Reorder(ctx context.Context, order projectmodels.ProjectOrder) ([]projectmodels.Project, error)
+	// UpdateStatus changes the publication status of the entry with the given ID and returns it in the default locale.
+	UpdateStatus(ctx context.Context, id uint, status projectmodels.ProjectStatus) (projectmodels.Project, error)
+	// PublishScheduled publishes the drafts whose publication time has passed. It is meant to be registered on a jobs.Scheduler.
+	PublishScheduled(ctx context.Context) error
+	// SyncRepositories reads the metadata of the repository of every entry from GitHub or GitLab and stores it
+	// with the entry. Links to other hosts are skipped, and so are providers whose rate limit is exhausted,
+	// until the limit resets. It is meant to be registered on a jobs.Scheduler.
+	SyncRepositories(ctx context.Context) error
+	// LinkChecks returns the status history of the links of the entry with the given ID, the most recent check first.
+	LinkChecks(ctx context.Context, id uint) ([]projectmodels.ProjectLinkCheck, error)
+	// MissingTranslations reports the translated locales lacking the title or description of an entry.
+	MissingTranslations(ctx context.Context) ([]i18n.MissingTranslation, error)
+	// AddImage sanitizes data with images.Sanitize, stores it together with its images.Variants and placeholder,
+	// and appends it to the gallery of the entry with the given ID, with the alt text, caption and cover flag of image.
+	// It returns the entry in the default locale, the errors of images.Sanitize for invalid images
+	// and ErrStorage when the image cannot be stored.
+	AddImage(ctx context.Context, id uint, data []byte, image projectmodels.ProjectImage) (projectmodels.Project, error)
+	// UpdateImage changes the alt text, caption or cover flag of an image of the gallery and returns the entry in the default locale.
+	UpdateImage(ctx context.Context, id uint, imageID uint, update projectmodels.ProjectImageUpdate) (projectmodels.Project, error)
+	// ReorderImages sets the display order of the gallery and returns the entry in the default locale.
+	// It returns projectrepositories.ErrImageOrder when ids are not the images of the gallery, each exactly once.
+	ReorderImages(ctx context.Context, id uint, ids []uint) (projectmodels.Project, error)
+	// DeleteImage removes an image from the gallery and deletes it from the storage.
+	DeleteImage(ctx context.Context, id uint, imageID uint) error
+}
+
+// ErrStorage is returned when an uploaded image cannot be written to the storage.
+var ErrStorage = errors.New("storage error")
+
+// MovedError is returned by FindBySlug for a previous slug of an entry.
+type MovedError struct {
+	Slug string // Current slug of the entry
+}
+
+func (e *MovedError) Error() string {
+	return "project moved to " + e.Slug
+}
+
+// service is the default implementation of Service.
+type service struct {
+	repo    projectrepositories.Repository
+	cache   cache.Cache
+	locales *i18n.Locales
+	store   storage.Storage
+	limits  images.Limits
+	hosting *githosting.Client
+}
+
+// NewService returns a Service that stores entries in repo, caches reads in c,
+// translates entries into locales, stores uploaded images within limits in store
+// and syncs the metadata of repositories through hosting.
+// It panics when a dependency is nil, so that a missing dependency fails at startup.
+func NewService(repo projectrepositories.Repository, c cache.Cache, locales *i18n.Locales, store storage.Storage, limits images.Limits,
+	hosting *githosting.Client) Service {
+	if repo == nil || c == nil || locales == nil || store == nil || hosting == nil {
+		panic("projectservices: nil dependency")
+	}
+	return &service{repo: repo, cache: c, locales: locales, store: store, limits: limits, hosting: hosting}
+}
+
+func (s *service) Create(ctx context.Context, project *projectmodels.Project) error {
+	if project.Translations == nil {
+		project.Translations = []projectmodels.ProjectTranslation{}
+	}
+	if project.Links == nil {
+		project.Links = []projectmodels.ProjectLink{}
+	}
+	// Entries are only published through UpdateStatus
+	project.Status, project.PublishAt = projectmodels.StatusDraft, nil
+	// The fields describing the cover are only set from the gallery, and the repository metadata by SyncRepositories
+	project.Repository = projectmodels.RepositoryMetadata{Topics: []string{}}
+	project.ImageWidth, project.ImageHeight = 0, 0
+	project.ImageVariants, project.ImageBlurHash, project.ImageColor = []projectmodels.ImageVariant{}, "", ""
+	if err := s.repo.Create(ctx, project); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx)
+
+	localized, err := s.localize(*project, s.locales.Default())
+	if err != nil {
+		return err
+	}
+	*project = localized
+	return nil
+}
+
+func (s *service) Update(ctx context.Context, project *projectmodels.Project) error {
+	if err := s.repo.Update(ctx, project); err != nil {
+		return err
+	}
+	updated, err := s.changed(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	*project = updated
+	return nil
+}
+
+func (s *service) FindByID(ctx context.Context, id uint, locale string, drafts bool) (projectmodels.Project, error) {
+	// Only what the public sees is cached
+	if drafts {
+		project, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return project, err
+		}
+		projects := []projectmodels.Project{project}
+		if err := s.flagBrokenLinks(ctx, projects); err != nil {
+			return project, err
+		}
+		return s.localize(projects[0], locale)
+	}
+
+	return cache.Load(ctx, s.cache, cacheKey(id, locale), func(ctx context.Context) (projectmodels.Project, error) {
+		project, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return project, err
+		}
+		if project.Status != projectmodels.StatusPublished {
+			return projectmodels.Project{}, fmt.Errorf("%w: project %d is %s", repositories.ErrNotFound, id, project.Status)
+		}
+		return s.localize(project, locale)
+	})
+}
+
+func (s *service) FindBySlug(ctx context.Context, slug string, locale string, drafts bool) (projectmodels.Project, error) {
+	// Slugs are resolved from the database, so the cache only holds entries by ID and is unaffected by renames
+	id, current, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return projectmodels.Project{}, err
+	}
+	// The entry is loaded first, so that previous slugs of hidden entries do not reveal their current slug
+	project, err := s.FindByID(ctx, id, locale, drafts)
+	if err != nil {
+		return project, err
+	}
+	if current != slug {
+		return projectmodels.Project{}, &MovedError{Slug: current}
+	}
+	return project, nil
+}
+
+func (s *service) BackfillSlugs(ctx context.Context) error {
+	ids, err := s.repo.BackfillSlugs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Printf("Generated the slug of %d projects: %v", len(ids), ids)
+		s.invalidate(ctx, ids...)
+	}
+	return nil
+}
+
+func (s *service) FindAll(ctx context.Context, locale string, filter projectrepositories.Filter) ([]projectmodels.Project, error) {
+	load := func(ctx context.Context) ([]projectmodels.Project, error) {
+		projects, err := s.repo.FindAll(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Drafts {
+			if err := s.flagBrokenLinks(ctx, projects); err != nil {
+				return nil, err
+			}
+		}
+		for i := range projects {
+			if projects[i], err = s.localize(projects[i], locale); err != nil {
+				return nil, err
+			}
+			// Lists only show the cover, which localize copied into the entry
+			projects[i].Images = nil
+		}
+		return projects, nil
+	}
+
+	// Only what the public sees is cached
+	switch filter {
+	case projectrepositories.Filter{}:
+		return cache.Load(ctx, s.cache, allKey(locale), load)
+	case projectrepositories.Filter{Featured: true}:
+		return cache.Load(ctx, s.cache, featuredKey(locale), load)
+	default:
+		return load(ctx)
+	}
+}
+
+func (s *service) Reorder(ctx context.Context, order projectmodels.ProjectOrder) ([]projectmodels.Project, error) {
+	if err := s.repo.Reorder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	projects, err := s.FindAll(ctx, s.locales.Default(), projectrepositories.Filter{Drafts: true})
+	if err != nil {
+		return nil, err
+	}
+	// The position of every entry may have changed
+	ids := make([]uint, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+	}
+	s.invalidate(ctx, ids...)
+	return projects, nil
+}
+
+func (s *service) UpdateStatus(ctx context.Context, id uint, status projectmodels.ProjectStatus) (projectmodels.Project, error) {
+	if err := s.repo.UpdateStatus(ctx, id, status); err != nil {
+		return projectmodels.Project{}, err
+	}
+	return s.changed(ctx, id)
+}
+
+func (s *service) PublishScheduled(ctx context.Context) error {
+	ids, err := s.repo.PublishDue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Printf("Published %d scheduled projects: %v", len(ids), ids)
+		s.invalidate(ctx, ids...)
+	}
+	return nil
+}
+
+func (s *service) SyncRepositories(ctx context.Context) error {
+	projects, err := s.repo.FindRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	var synced []uint
+	var failures []error
+	for _, project := range projects {
+		repository, ok := s.hosting.Parse(project.RepositoryLink)
+		if !ok {
+			continue
+		}
+
+		fetched, err := s.hosting.Fetch(ctx, repository, project.Repository.ETag)
+		metadata := projectmodels.RepositoryMetadata{
+			Stars: fetched.Stars, Forks: fetched.Forks, Language: fetched.Language, Topics: fetched.Topics,
+			PushedAt: fetched.PushedAt, ETag: fetched.ETag,
+		}
+		result := "updated"
+		switch {
+		case errors.Is(err, githosting.ErrNotModified):
+			metadata, result = project.Repository, "not_modified"
+		case errors.Is(err, githosting.ErrNotFound):
+			// Broken links are reported by the link checker rather than failing every run
+			log.Printf("Repository of project %d not found: %s", project.ID, project.RepositoryLink)
+			result = "not_found"
+		case errors.Is(err, githosting.ErrRateLimited):
+			result = "rate_limited"
+		case err != nil:
+			failures = append(failures, fmt.Errorf("project %d: %w", project.ID, err))
+			result = "failure"
+		}
+		metrics.RepositorySyncs.WithLabelValues(repository.Provider, result).Inc()
+		if result != "updated" && result != "not_modified" {
+			continue
+		}
+
+		now := time.Now()
+		metadata.SyncedAt = &now
+		if err := s.repo.UpdateRepository(ctx, project.ID, project.RepositoryLink, metadata); err != nil {
+			failures = append(failures, fmt.Errorf("project %d: %w", project.ID, err))
+			continue
+		}
+		synced = append(synced, project.ID)
+	}
+
+	if len(synced) > 0 {
+		s.invalidate(ctx, synced...)
+	}
+	return errors.Join(failures...)
+}
+
+func (s *service) LinkChecks(ctx context.Context, id uint) ([]projectmodels.ProjectLinkCheck, error) {
+	return s.repo.LinkChecks(ctx, id)
+}
+
+func (s *service) MissingTranslations(ctx context.Context) ([]i18n.MissingTranslation, error) {
+	projects, err := s.repo.FindAll(ctx, projectrepositories.Filter{Drafts: true})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []i18n.MissingTranslation{}
+	for _, project := range projects {
+		for _, locale := range s.locales.Translated() {
+			var translation projectmodels.ProjectTranslation
+			for _, t := range project.Translations {
+				if t.Locale == locale {
+					translation = t
+				}
+			}
+
+			var fields []string
+			if project.ProjectTitle != "" && translation.ProjectTitle == "" {
+				fields = append(fields, "projectTitle")
+			}
+			if project.ProjectDescription != "" && translation.ProjectDescription == "" {
+				fields = append(fields, "projectDescription")
+			}
+			if len(fields) > 0 {
+				missing = append(missing, i18n.MissingTranslation{Entity: "project", ID: project.ID, Locale: locale, Fields: fields})
+			}
+		}
+	}
+	return missing, nil
+}
+
+func (s *service) AddImage(ctx context.Context, id uint, data []byte, image projectmodels.ProjectImage) (projectmodels.Project, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return projectmodels.Project{}, err
+	}
+
+	img, err := images.Sanitize(data, s.limits.MaxPixels)
+	if err != nil {
+		return projectmodels.Project{}, err
+	}
+	variants, err := images.Variants(img)
+	if err != nil {
+		return projectmodels.Project{}, err
+	}
+	placeholder, err := images.NewPlaceholder(img)
+	if err != nil {
+		return projectmodels.Project{}, err
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return projectmodels.Project{}, err
+	}
+	base := fmt.Sprintf("projects/%d/%s", id, hex.EncodeToString(name))
+
+	image.ProjectID, image.Width, image.Height = id, img.Width, img.Height
+	image.BlurHash, image.Color = placeholder.BlurHash, placeholder.Color
+	image.Variants = make([]projectmodels.ImageVariant, 0, len(variants))
+	image.VariantKeys = make([]string, 0, len(variants))
+
+	// Objects stored before a failure are deleted again, so that a failed upload leaves nothing behind
+	var stored []string
+	put := func(key string, data []byte, contentType string) (string, error) {
+		url, err := s.store.Put(ctx, key, data, contentType)
+		if err != nil {
+			s.deleteImages(ctx, stored...)
+			return "", fmt.Errorf("%w: %v", ErrStorage, err)
+		}
+		stored = append(stored, key)
+		return url, nil
+	}
+
+	image.Key = base + img.Extension
+	if image.URL, err = put(image.Key, img.Data, img.ContentType); err != nil {
+		return projectmodels.Project{}, err
+	}
+	for _, variant := range variants {
+		key := base + "-" + variant.Name + variant.Extension
+		url, err := put(key, variant.Data, variant.ContentType)
+		if err != nil {
+			return projectmodels.Project{}, err
+		}
+		image.VariantKeys = append(image.VariantKeys, key)
+		image.Variants = append(image.Variants, projectmodels.ImageVariant{
+			Name: variant.Name, URL: url, Width: variant.Width, Height: variant.Height, ContentType: variant.ContentType,
+		})
+	}
+
+	if err := s.repo.AddImage(ctx, &image); err != nil {
+		s.deleteImages(ctx, stored...)
+		return projectmodels.Project{}, err
+	}
+	return s.changed(ctx, id)
+}
+
+func (s *service) UpdateImage(ctx context.Context, id uint, imageID uint, update projectmodels.ProjectImageUpdate) (projectmodels.Project, error) {
+	if err := s.repo.UpdateImage(ctx, id, imageID, update); err != nil {
+		return projectmodels.Project{}, err
+	}
+	return s.changed(ctx, id)
+}
+
+func (s *service) ReorderImages(ctx context.Context, id uint, ids []uint) (projectmodels.Project, error) {
+	if err := s.repo.ReorderImages(ctx, id, ids); err != nil {
+		return projectmodels.Project{}, err
+	}
+	return s.changed(ctx, id)
+}
+
+func (s *service) DeleteImage(ctx context.Context, id uint, imageID uint) error {
+	image, err := s.repo.DeleteImage(ctx, id, imageID)
+	if err != nil {
+		return err
+	}
+	s.deleteImages(ctx, append([]string{image.Key}, image.VariantKeys...)...)
+	s.invalidate(ctx, id)
+	return nil
+}
+
+// changed invalidates the cached entry with the given ID after a change to it or its gallery
+// and returns it from the database in the default locale, with its broken links flagged for the admin.
+func (s *service) changed(ctx context.Context, id uint) (projectmodels.Project, error) {
+	s.invalidate(ctx, id)
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return projectmodels.Project{}, err
+	}
+	projects := []projectmodels.Project{project}
+	if err := s.flagBrokenLinks(ctx, projects); err != nil {
+		return projectmodels.Project{}, err
+	}
+	return s.localize(projects[0], s.locales.Default())
+}
+
+// flagBrokenLinks sets the BrokenLinks of projects to the last checks that found one of their current links broken.
+// Checks of links that were changed since are left out.
+func (s *service) flagBrokenLinks(ctx context.Context, projects []projectmodels.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+	}
+	checks, err := s.repo.LatestLinkChecks(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range projects {
+		current := map[string]bool{}
+		for _, link := range checkedLinks(projects[i]) {
+			current[link.url] = true
+		}
+		for _, check := range checks {
+			if check.ProjectID == projects[i].ID && check.Broken && current[check.URL] {
+				projects[i].BrokenLinks = append(projects[i].BrokenLinks, check)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteImages removes uploaded images that are no longer used. A failure only leaves an
+// orphaned object behind, so it is logged instead of returned.
+func (s *service) deleteImages(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("Error deleting image %s from storage: %v", key, err)
+		}
+	}
+}
+
+// invalidate removes the cached lists of entries, featured or not, and the cached entries with the given IDs in every locale.
+func (s *service) invalidate(ctx context.Context, ids ...uint) {
+	var keys []string
+	for _, locale := range s.locales.Supported() {
+		keys = append(keys, allKey(locale), featuredKey(locale))
+		for _, id := range ids {
+			keys = append(keys, cacheKey(id, locale))
+		}
+	}
+	cache.Invalidate(ctx, s.cache, keys...)
+}
+
+// localize returns project with its translated fields in locale, its description and the bodies of its
+// sections rendered into HTML and the image fields taken from the cover of its gallery, if any.
+// Entries without a cover, links or repository topics get empty lists rather than null.
+func (s *service) localize(project projectmodels.Project, locale string) (projectmodels.Project, error) {
+	titles := map[string]string{s.locales.Default(): project.ProjectTitle}
+	descriptions := map[string]string{s.locales.Default(): project.ProjectDescription}
+	for _, translation := range project.Translations {
+		titles[translation.Locale] = translation.ProjectTitle
+		descriptions[translation.Locale] = translation.ProjectDescription
+	}
+	project.ProjectTitle = s.locales.Pick(locale, titles)
+	project.ProjectDescription = s.locales.Pick(locale, descriptions)
+	project.Locale = locale
+	for _, image := range project.Images {
+		if image.Cover {
+			project.Image, project.ImageTitle = image.URL, image.Alt
+			project.ImageWidth, project.ImageHeight, project.ImageVariants = image.Width, image.Height, image.Variants
+			project.ImageBlurHash, project.ImageColor = image.BlurHash, image.Color
+		}
+	}
+	if project.ImageVariants == nil {
+		project.ImageVariants = []projectmodels.ImageVariant{}
+	}
+	if project.Links == nil {
+		project.Links = []projectmodels.ProjectLink{}
+	}
+	if project.Repository.Topics == nil {
+		project.Repository.Topics = []string{}
+	}
+
+	html, err := markdown.Render(project.ProjectDescription)
+	if err != nil {
+		return project, err
+	}
+	project.ProjectDescriptionHTML = html
+	for i := range project.Sections {
+		if project.Sections[i].BodyHTML, err = markdown.Render(project.Sections[i].Body); err != nil {
+			return project, err
+		}
+	}
+	return project, nil
+}
+
+// cacheKey returns the cache key of a single entry in locale.
+func cacheKey(id uint, locale string) string {
+	return "project:" + strconv.FormatUint(uint64(id), 10) + ":" + locale
+}
+
+// featuredKey returns the cache key of the list of featured entries in locale.
+func featuredKey(locale string) string {
+	return "project:featured:" + locale
+}
+
+// allKey returns the cache key of the list of entries in locale.
+func allKey(locale string) string {
+	return "project:all:" + locale
+}
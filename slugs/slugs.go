@@ -0,0 +1,66 @@
+// Package slugs turns titles into the human-readable identifiers used in URLs.
+//
+// Titles are transliterated into ASCII first, so that "Café Ünïcode" becomes "cafe-unicode" and
+// "Привет мир" becomes "privet-mir". Slugs never consist of digits only, so that a path segment
+// can hold either a numeric ID or a slug.
+package slugs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+// MaxLength is the maximum length of a slug, suffix included.
+const MaxLength = 80
+
+// Pattern matches every slug returned by Make and WithSuffix.
+const Pattern = `^[a-z0-9_-]*[a-z_-][a-z0-9_-]*$`
+
+// Make returns the slug of title, cut at a word boundary to leave room for a suffix added by WithSuffix.
+// Titles without any letter or digit get fallback, and titles made of digits only are prefixed with it.
+func Make(title string, fallback string) string {
+	s := truncate(slug.Make(title), MaxLength-suffixLength)
+	switch {
+	case s == "":
+		return fallback
+	case isNumeric(s):
+		return truncate(fallback+"-"+s, MaxLength-suffixLength)
+	}
+	return s
+}
+
+// WithSuffix returns the n-th candidate for base when the previous ones are taken: base itself
+// for n = 1, then base-2, base-3 and so on.
+func WithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// suffixLength is the room left by Make for the suffix of WithSuffix, up to "-9999".
+const suffixLength = 5
+
+// truncate cuts s to at most n bytes, at the last hyphen when there is one.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "-_")
+}
+
+// isNumeric reports whether s consists of ASCII digits only.
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
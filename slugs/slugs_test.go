@@ -0,0 +1,80 @@
+package slugs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Go Portfolio Service", "go-portfolio-service"},
+		{"  Hello,   World!  ", "hello-world"},
+		{"Café Ünïcode", "cafe-unicode"},
+		{"Привет мир", "privet-mir"},
+		{"Ελληνικά", "ellenika"},
+		{"2024", "project-2024"},
+		{"2024!", "project-2024"},
+		{"2024 Review", "2024-review"},
+		{"!!!", "project"},
+		{"", "project"},
+		{long, strings.TrimSuffix(strings.Repeat("word-", 15), "-")},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.title, "project"); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMakeMatchesPattern(t *testing.T) {
+	pattern := regexp.MustCompile(Pattern)
+	titles := []string{"Go Portfolio", "Привет мир", "12345", strings.Repeat("9", 200), strings.Repeat("long-title ", 20)}
+	for _, title := range titles {
+		s := WithSuffix(Make(title, "project"), 9999)
+		if !pattern.MatchString(s) || len(s) > MaxLength {
+			t.Errorf("WithSuffix(Make(%q), 9999) = %q, want at most %d characters matching %s", title, s, MaxLength, Pattern)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly-ten", 11, "exactly-ten"},
+		{"cut-at-hyphen", 10, "cut-at"},
+		{"cut-at-hyphen", 7, "cut-at"},
+		{"nohyphenatall", 5, "nohyp"},
+		{"trailing_-x", 10, "trailing"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "portfolio"},
+		{1, "portfolio"},
+		{2, "portfolio-2"},
+		{10, "portfolio-10"},
+	}
+	for _, tt := range tests {
+		if got := WithSuffix("portfolio", tt.n); got != tt.want {
+			t.Errorf("WithSuffix(portfolio, %d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package storage stores uploaded files and returns the public URL they are served from.
+//
+// Two backends are available: Local writes files to a directory served by the application,
+// and S3 uploads them to an S3-compatible object store (AWS S3, MinIO, Cloudflare R2, ...).
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/EkoAgustina/go-ms-portfolio/utils"
+)
+
+// Storage stores objects under keys such as "projects/1/3f2a.jpg".
+type Storage interface {
+	// Put stores data under key with the given content type and returns the public URL of the object.
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Load creates the storage backend configured by environment variables.
+// It exits the application when the configuration is invalid.
+//
+// Environment Variables:
+//   - STORAGE_BACKEND: local or s3 (default local).
+//   - STORAGE_LOCAL_DIR: Directory of the local backend (default "uploads").
+//   - STORAGE_PUBLIC_URL: Base URL of the stored objects (default "/uploads" for the local backend,
+//     and the bucket URL for the s3 backend).
+//   - S3_ENDPOINT: URL of the S3-compatible service, e.g. https://s3.eu-central-1.amazonaws.com.
+//   - S3_REGION: Region used to sign requests (default us-east-1).
+//   - S3_BUCKET, S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY: Bucket and credentials.
+//   - S3_PATH_STYLE: Address the bucket in the path rather than the host name (default true).
+func Load() Storage {
+	switch backend := utils.LoadEnvDefault("STORAGE_BACKEND", "local"); backend {
+	case "local":
+		local, err := NewLocal(utils.LoadEnvDefault("STORAGE_LOCAL_DIR", "uploads"), utils.LoadEnvDefault("STORAGE_PUBLIC_URL", "/uploads"))
+		if err != nil {
+			log.Fatalf("Error creating the local storage: %v", err)
+		}
+		return local
+	case "s3":
+		s3, err := NewS3(S3Config{
+			Endpoint:        utils.LoadEnv("S3_ENDPOINT"),
+			Region:          utils.LoadEnvDefault("S3_REGION", "us-east-1"),
+			Bucket:          utils.LoadEnv("S3_BUCKET"),
+			AccessKeyID:     utils.LoadEnv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: utils.LoadEnv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       utils.LoadEnvDefault("STORAGE_PUBLIC_URL", ""),
+			PathStyle:       utils.LoadEnvBool("S3_PATH_STYLE", true),
+		})
+		if err != nil {
+			log.Fatalf("Error creating the S3 storage: %v", err)
+		}
+		return s3
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND %q, expected local or s3", backend)
+		return nil
+	}
+}
+
+// checkKey rejects keys that could escape the storage root or are not portable object names.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+// joinURL appends key to the base URL.
+func joinURL(base string, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -3,6 +3,8 @@ package utils
 import (
     "log"
     "os"
+    "strconv"
+    "time"
 
     "github.com/joho/godotenv"
 )
@@ -35,3 +37,72 @@ func LoadEnv(key string) string {
 
     return value
 }
+
+// LoadEnvDefault works like LoadEnv but returns fallback instead of exiting
+// when the environment variable is not set or empty.
+//
+// Example:
+//   interval := utils.LoadEnvDefault("RETENTION_INTERVAL", "24h")
+func LoadEnvDefault(key string, fallback string) string {
+    envFile := os.Getenv("ENV_FILE")
+
+    if err := godotenv.Load(envFile); err != nil {
+        log.Fatalf("Error loading .env file: %v", err)
+    }
+
+    value := os.Getenv(key)
+    if len(value) == 0 {
+        return fallback
+    }
+
+    return value
+}
+
+// LoadEnvInt returns the integer value of an optional environment variable.
+// It returns fallback when the variable is not set and exits the application
+// when the value is not a valid integer.
+func LoadEnvInt(key string, fallback int) int {
+    value := LoadEnvDefault(key, "")
+    if value == "" {
+        return fallback
+    }
+
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        log.Fatalf("Environment variable %s must be an integer: %v", key, err)
+    }
+
+    return n
+}
+
+// LoadEnvBool returns the boolean value of an optional environment variable.
+// It accepts the values understood by strconv.ParseBool.
+func LoadEnvBool(key string, fallback bool) bool {
+    value := LoadEnvDefault(key, "")
+    if value == "" {
+        return fallback
+    }
+
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        log.Fatalf("Environment variable %s must be a boolean: %v", key, err)
+    }
+
+    return b
+}
+
+// LoadEnvDuration returns the duration value of an optional environment variable,
+// written in the format understood by time.ParseDuration (e.g. "30s", "24h").
+func LoadEnvDuration(key string, fallback time.Duration) time.Duration {
+    value := LoadEnvDefault(key, "")
+    if value == "" {
+        return fallback
+    }
+
+    d, err := time.ParseDuration(value)
+    if err != nil {
+        log.Fatalf("Environment variable %s must be a duration: %v", key, err)
+    }
+
+    return d
+}